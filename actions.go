@@ -27,6 +27,15 @@ func (bot *robot) checkReviewer(e *sdk.PullRequestEvent, cfg *botConfig) error {
 		return nil
 	}
 
+	// the reviewer will be checked when the pr leaves draft.
+	if e.GetPullRequest() != nil && isWorkInProgress(e.GetPullRequest()) {
+		return nil
+	}
+
+	return bot.remindReviewer(e)
+}
+
+func (bot *robot) remindReviewer(e *sdk.PullRequestEvent) error {
 	if e.GetPullRequest() != nil && len(e.GetPullRequest().Assignees) > 0 {
 		return nil
 	}
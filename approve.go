@@ -44,7 +44,7 @@ func (bot *robot) AddApprove(cfg *botConfig, e giteeclient.PRNoteEvent, log *log
 	}
 
 	if !v {
-		return bot.cli.CreatePRComment(pr.Org, pr.Repo, pr.Number, fmt.Sprintf(
+		return bot.notifyInStatusComment(e, cfg, fmt.Sprintf(
 			commentNoPermissionForLabel, commenter, "add", approvedLabel,
 		))
 	}
@@ -66,7 +66,7 @@ func (bot *robot) removeApprove(cfg *botConfig, e giteeclient.PRNoteEvent, log *
 	}
 
 	if !v {
-		return bot.cli.CreatePRComment(pr.Org, pr.Repo, pr.Number, fmt.Sprintf(
+		return bot.notifyInStatusComment(e, cfg, fmt.Sprintf(
 			commentNoPermissionForLabel, commenter, "remove", approvedLabel,
 		))
 	}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/opensourceways/community-robot-lib/giteeclient"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	defaultBackportApprovedLabel = "backport-approved"
+
+	checkNameBackportApproval = "Backport approval"
+
+	commentBackportApproveNotNeeded = "@%s , the target branch ***%s*** of this pull request does not need the backport approval."
+	commentNotReleaseManager        = "@%s , only the release managers: %s can approve the backport into the branch ***%s***."
+	commentBackportApproved         = "The backport into the branch ***%s*** is approved by the release manager @%s."
+	commentBackportApproveCanceled  = "The backport approval of the branch ***%s*** is canceled by the release manager @%s."
+	commentBackportApprovalRevoked  = "@%s , the backport approval is revoked because the branches of this pull request are changed, please ask the release managers to approve it again by `/backport-approve`."
+
+	msgBackportApproved    = "The backport is approved by the release managers: %s"
+	msgBackportNotApproved = "The backport needs the approval of the release managers: %s by `/backport-approve`."
+	msgFailedToGetComments = "Failed to get the comments of PR."
+)
+
+var regBackportApprove = regexp.MustCompile(`(?mi)^/backport-approve(\s+cancel)?\s*$`)
+
+// backportApproval requires the PR targeting the branches to be approved by release managers.
+type backportApproval struct {
+	// Branches are the glob patterns of target branches, such as 'openEuler-22.03-LTS*'.
+	Branches []string `json:"branches" required:"true"`
+
+	// Label is the label granted by the command of /backport-approve. The default is backport-approved.
+	Label string `json:"label,omitempty"`
+
+	// ReleaseManagers are the logins who can use the command of /backport-approve.
+	ReleaseManagers []string `json:"release_managers" required:"true"`
+}
+
+func (b *backportApproval) setDefault() {
+	if b.Label == "" {
+		b.Label = defaultBackportApprovedLabel
+	}
+}
+
+func (b *backportApproval) validate() error {
+	if len(b.Branches) == 0 {
+		return fmt.Errorf("missing branches of backport approval")
+	}
+
+	for _, p := range b.Branches {
+		if err := validatePattern(p); err != nil {
+			return fmt.Errorf("invalid branch pattern of backport approval: %s", p)
+		}
+	}
+
+	if len(b.ReleaseManagers) == 0 {
+		return fmt.Errorf("missing release_managers of backport approval")
+	}
+
+	return nil
+}
+
+func (b *backportApproval) isReleaseManager(login string) bool {
+	for _, v := range b.ReleaseManagers {
+		if strings.EqualFold(v, login) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getBackportApprovals returns the backport approvals which the target branch matches.
+func (c *botConfig) getBackportApprovals(branch string) []*backportApproval {
+	var r []*backportApproval
+
+	for i := range c.BackportApprovals {
+		if item := &c.BackportApprovals[i]; matchAnyPattern(item.Branches, branch) {
+			r = append(r, item)
+		}
+	}
+
+	return r
+}
+
+// handleBackportApprove handles the command of /backport-approve [cancel] which can be
+// used only by the release managers of the target branch.
+func (bot *robot) handleBackportApprove(e *sdk.NoteEvent, cfg *botConfig, log *logrus.Entry) error {
+	ne := giteeclient.NewPRNoteEvent(e)
+
+	if !ne.IsPullRequest() ||
+		!ne.IsPROpen() ||
+		!ne.IsCreatingCommentEvent() ||
+		!regBackportApprove.MatchString(ne.GetComment()) {
+		return nil
+	}
+
+	pr := ne.GetPRInfo()
+	commenter := ne.GetCommenter()
+
+	rules := cfg.getBackportApprovals(pr.BaseRef)
+	if len(rules) == 0 {
+		return bot.cli.CreatePRComment(pr.Org, pr.Repo, pr.Number, fmt.Sprintf(
+			commentBackportApproveNotNeeded, commenter, pr.BaseRef,
+		))
+	}
+
+	var labels []string
+	var managers []string
+	for _, item := range rules {
+		if item.isReleaseManager(commenter) {
+			labels = append(labels, item.Label)
+		}
+		managers = append(managers, item.ReleaseManagers...)
+	}
+
+	if len(labels) == 0 {
+		return bot.cli.CreatePRComment(pr.Org, pr.Repo, pr.Number, fmt.Sprintf(
+			commentNotReleaseManager, commenter, strings.Join(managers, ", "), pr.BaseRef,
+		))
+	}
+
+	cancel := strings.TrimSpace(regBackportApprove.FindStringSubmatch(ne.GetComment())[1]) != ""
+
+	comment := commentBackportApproved
+	if cancel {
+		comment = commentBackportApproveCanceled
+	}
+
+	// comment before setting the labels, because the labels are trusted only
+	// with the comment when the pr is re-evaluated on the label update.
+	if err := bot.cli.CreatePRComment(pr.Org, pr.Repo, pr.Number, fmt.Sprintf(comment, pr.BaseRef, commenter)); err != nil {
+		return err
+	}
+
+	for _, l := range labels {
+		if err := bot.setBackportLabel(pr, l, !cancel, log); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (bot *robot) setBackportLabel(pr giteeclient.PRInfo, label string, approved bool, log *logrus.Entry) error {
+	hasLabel := pr.Labels.Has(label)
+
+	if !approved {
+		if hasLabel {
+			return bot.cli.RemovePRLabel(pr.Org, pr.Repo, pr.Number, label)
+		}
+
+		return nil
+	}
+
+	if hasLabel {
+		return nil
+	}
+
+	if err := bot.createLabelIfNeed(pr.Org, pr.Repo, label); err != nil {
+		log.WithError(err).Errorf("create repo label: %s", label)
+	}
+
+	return bot.cli.AddPRLabel(pr.Org, pr.Repo, pr.Number, label)
+}
+
+// checkBackportApproval checks whether the backport is approved by the release managers of item.
+func (m *mergeHelper) checkBackportApproval(item *backportApproval, labels sets.String) mergeCheck {
+	r := mergeCheck{name: checkNameBackportApproval}
+	managers := strings.Join(item.ReleaseManagers, ", ")
+
+	approved, err := m.isBackportApproved(item, labels)
+	if err != nil {
+		r.detail = msgFailedToGetComments
+		r.err = err
+
+		return r
+	}
+
+	if approved {
+		r.passed = true
+		r.detail = fmt.Sprintf(msgBackportApproved, managers)
+	} else {
+		r.detail = fmt.Sprintf(msgBackportNotApproved, managers)
+	}
+
+	return r
+}
+
+// isBackportApproved checks whether the label of backport approval is trusted.
+func (m *mergeHelper) isBackportApproved(item *backportApproval, labels sets.String) (bool, error) {
+	return m.isLabelTrusted(item.Label, labels, item.decision(m.pr.GetBase().GetRef()))
+}
+
+// decision decides on the backport into the branch by the approval or the cancellation of
+// one of the release managers. The revocation cancels the approval too.
+func (b *backportApproval) decision(branch string) commentDecision {
+	return func(comment string) (bool, bool) {
+		if v, ok := parseComment(comment, commentBackportApproved); ok && v[0] == branch && b.isReleaseManager(v[1]) {
+			return true, true
+		}
+
+		if v, ok := parseComment(comment, commentBackportApproveCanceled); ok && v[0] == branch && b.isReleaseManager(v[1]) {
+			return false, true
+		}
+
+		return false, isCommentOf(comment, commentBackportApprovalRevoked)
+	}
+}
+
+// handleBackportApprovalReset revokes the backport approvals when the branches of pr are
+// changed, because the new commits or the new target branch are not approved.
+func (bot *robot) handleBackportApprovalReset(e *sdk.PullRequestEvent, cfg *botConfig) error {
+	if a := giteeclient.GetPullRequestAction(e); a != giteeclient.PRActionChangedSourceBranch &&
+		a != giteeclient.PRActionChangedTargetBranch {
+		return nil
+	}
+
+	pr := giteeclient.GetPRInfoByPREvent(e)
+
+	labels := sets.NewString()
+	for i := range cfg.BackportApprovals {
+		if l := cfg.BackportApprovals[i].Label; pr.Labels.Has(l) {
+			labels.Insert(l)
+		}
+	}
+
+	if labels.Len() == 0 {
+		return nil
+	}
+
+	if err := bot.cli.RemovePRLabels(pr.Org, pr.Repo, pr.Number, labels.List()); err != nil {
+		return err
+	}
+
+	return bot.cli.CreatePRComment(pr.Org, pr.Repo, pr.Number, fmt.Sprintf(
+		commentBackportApprovalRevoked, pr.Author,
+	))
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBackportDecision(t *testing.T) {
+	const branch = "openEuler-22.03-LTS-SP1"
+
+	cases := []struct {
+		name     string
+		comment  string
+		approved bool
+		ok       bool
+	}{
+		{"approved", fmt.Sprintf(commentBackportApproved, branch, "manager"), true, true},
+		{"canceled", fmt.Sprintf(commentBackportApproveCanceled, branch, "manager"), false, true},
+		{"revoked", fmt.Sprintf(commentBackportApprovalRevoked, "author"), false, true},
+		{"approved for other branch", fmt.Sprintf(commentBackportApproved, "openEuler-20.03-LTS", "manager"), false, false},
+		{"approved by other manager", fmt.Sprintf(commentBackportApproved, branch, "someone"), false, false},
+		{"canceled by other manager", fmt.Sprintf(commentBackportApproveCanceled, branch, "someone"), false, false},
+	}
+
+	decide := (&backportApproval{ReleaseManagers: []string{"Manager"}}).decision(branch)
+
+	for _, c := range cases {
+		if approved, ok := decide(c.comment); approved != c.approved || ok != c.ok {
+			t.Errorf("%s: got %t, %t, want %t, %t", c.name, approved, ok, c.approved, c.ok)
+		}
+	}
+}
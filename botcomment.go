@@ -0,0 +1,109 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// commentDecision returns whether the comment of bot approves the label or cancels the approval.
+// ok is false if the comment is not a decision on the label.
+type commentDecision func(comment string) (approved bool, ok bool)
+
+// isLabelTrusted checks the label granted by a command of bot against the comments of bot,
+// because anyone who can edit the labels of pr is able to add it. The label is trusted only
+// if the latest decision of bot on it is the approval.
+func (m *mergeHelper) isLabelTrusted(label string, labels sets.String, decide commentDecision) (bool, error) {
+	if !labels.Has(label) {
+		return false, nil
+	}
+
+	comments, err := m.getComments()
+	if err != nil {
+		return false, err
+	}
+
+	approved := false
+
+	for i := range comments {
+		c := &comments[i]
+		if !m.isBotComment(c) {
+			continue
+		}
+
+		if v, ok := decide(c.Body); ok {
+			approved = v
+		}
+	}
+
+	return approved, nil
+}
+
+// dropUntrustedLabels returns the labels without the ones granted by the commands of bot which
+// are not trusted, so that the labels added by hand can't meet the conditions of labels.
+// The error of checking them is shown by the checks of backport approval and freeze.
+func (m *mergeHelper) dropUntrustedLabels(labels sets.String) sets.String {
+	r := sets.NewString(labels.List()...)
+	rules := m.cfg.getBackportApprovals(m.pr.GetBase().GetRef())
+
+	for i := range m.cfg.BackportApprovals {
+		label := m.cfg.BackportApprovals[i].Label
+		if !r.Has(label) {
+			continue
+		}
+
+		trusted := false
+		for _, item := range rules {
+			if item.Label == label {
+				if ok, _ := m.isBackportApproved(item, labels); ok {
+					trusted = true
+
+					break
+				}
+			}
+		}
+
+		if !trusted {
+			r.Delete(label)
+		}
+	}
+
+	if ok, _ := m.isFreezeExceptionApproved(labels); !ok {
+		r.Delete(freezeExceptionApprovedLabel)
+	}
+
+	return r
+}
+
+// isCommentOf checks whether the comment is generated from the format whose verbs are all %s.
+func isCommentOf(comment, format string) bool {
+	_, ok := parseComment(comment, format)
+
+	return ok
+}
+
+// parseComment returns the arguments of the comment generated from the format whose verbs are all %s.
+func parseComment(comment, format string) ([]string, bool) {
+	parts := strings.Split(format, "%s")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+
+	reg, err := regexp.Compile("(?s)^" + strings.Join(parts, "(.*)") + "$")
+	if err != nil {
+		return nil, false
+	}
+
+	m := reg.FindStringSubmatch(comment)
+	if m == nil {
+		return nil, false
+	}
+
+	return m[1:], true
+}
+
+func (m *mergeHelper) isBotComment(c *sdk.PullRequestComments) bool {
+	return c.User != nil && strings.EqualFold(c.User.Login, m.botLogin)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// fakeCommentClient lists the comments of pr. The other methods of iClient are not implemented.
+type fakeCommentClient struct {
+	iClient
+
+	comments []sdk.PullRequestComments
+}
+
+func (c *fakeCommentClient) ListPRComments(org, repo string, number int32) ([]sdk.PullRequestComments, error) {
+	return c.comments, nil
+}
+
+func newComment(login, format string, a ...interface{}) sdk.PullRequestComments {
+	return sdk.PullRequestComments{Body: fmt.Sprintf(format, a...), User: &sdk.UserBasic{Login: login}}
+}
+
+func TestIsLabelTrusted(t *testing.T) {
+	const (
+		label     = "approved-by-command"
+		approve   = "Approved by @%s."
+		cancel    = "Canceled by @%s."
+		unrelated = "Hello @%s."
+	)
+
+	decide := func(comment string) (bool, bool) {
+		if isCommentOf(comment, approve) {
+			return true, true
+		}
+
+		return false, isCommentOf(comment, cancel)
+	}
+
+	approved := newComment("robot", approve, "owner")
+	canceled := newComment("robot", cancel, "owner")
+	other := newComment("robot", unrelated, "owner")
+	forged := newComment("someone", approve, "owner")
+
+	cases := []struct {
+		name     string
+		label    bool
+		comments []sdk.PullRequestComments
+		trusted  bool
+	}{
+		{"approved", true, []sdk.PullRequestComments{approved}, true},
+		{"no label", false, []sdk.PullRequestComments{approved}, false},
+		{"label added by human", true, nil, false},
+		{"forged comment", true, []sdk.PullRequestComments{forged}, false},
+		{"canceled", true, []sdk.PullRequestComments{approved, canceled}, false},
+		{"approved again", true, []sdk.PullRequestComments{approved, canceled, approved}, true},
+		{"the other comments are ignored", true, []sdk.PullRequestComments{approved, other}, true},
+	}
+
+	for _, c := range cases {
+		h := mergeHelper{
+			pr:       &sdk.PullRequestHook{Number: 1},
+			org:      "org",
+			repo:     "repo",
+			cli:      &fakeCommentClient{comments: c.comments},
+			botLogin: "Robot",
+		}
+
+		labels := sets.NewString()
+		if c.label {
+			labels.Insert(label)
+		}
+
+		if v, err := h.isLabelTrusted(label, labels, decide); err != nil || v != c.trusted {
+			t.Errorf("%s: got %t, %v, want %t", c.name, v, err, c.trusted)
+		}
+	}
+}
+
+func TestDropUntrustedLabels(t *testing.T) {
+	const branch = "openEuler-22.03-LTS-SP1"
+
+	cfg := &botConfig{BackportApprovals: []backportApproval{
+		{Branches: []string{"openEuler-22.03-LTS*"}, Label: "backport-approved", ReleaseManagers: []string{"manager"}},
+		{Branches: []string{"openEuler-20.03-LTS*"}, Label: "backport-20.03", ReleaseManagers: []string{"manager"}},
+	}}
+
+	labels := []string{"lgtm", "backport-approved", "backport-20.03", freezeExceptionApprovedLabel}
+
+	cases := []struct {
+		name     string
+		comments []sdk.PullRequestComments
+		want     []string
+	}{
+		{
+			name: "labels added by human",
+			want: []string{"lgtm"},
+		},
+		{
+			name:     "backport approved",
+			comments: []sdk.PullRequestComments{newComment("robot", commentBackportApproved, branch, "manager")},
+			want:     []string{"backport-approved", "lgtm"},
+		},
+		{
+			name:     "freeze exception approved",
+			comments: []sdk.PullRequestComments{newComment("robot", commentFreezeExceptionApproved, branch, "owner")},
+			want:     []string{freezeExceptionApprovedLabel, "lgtm"},
+		},
+	}
+
+	for _, c := range cases {
+		h := mergeHelper{
+			pr:       &sdk.PullRequestHook{Number: 1, Base: &sdk.BranchHook{Ref: branch}},
+			cfg:      cfg,
+			org:      "org",
+			repo:     "repo",
+			cli:      &fakeCommentClient{comments: c.comments},
+			botLogin: "robot",
+		}
+
+		if v := h.dropUntrustedLabels(sets.NewString(labels...)).List(); !reflect.DeepEqual(v, c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, v, c.want)
+		}
+	}
+}
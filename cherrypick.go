@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/opensourceways/community-robot-lib/giteeclient"
+	"github.com/opensourceways/community-robot-lib/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	msgCherryPickAfterMerged = "@%s , this pr will be cherry-picked to ***%s*** after it is merged."
+	msgCherryPickFailed      = "@%s , failed to cherry-pick this pr to ***%s***, please do it manually."
+	msgCherryPickDone        = "@%s , this pr has been cherry-picked to ***%s*** in %s"
+	msgCherryPickConflicts   = `@%s , failed to cherry-pick this pr to ***%s*** because of conflicts, please do it manually as below:
+` + "```" + `
+git fetch origin refs/pull/%d/head
+git checkout -b %s origin/%s
+git cherry-pick -x %s
+# resolve the conflicts, then run 'git cherry-pick --continue' and push the branch to create a pull request.
+` + "```"
+
+	cherryPickPRTitle = "[%s] %s"
+	cherryPickPRBody  = "This is an automated cherry-pick of #%d to ***%s***.\n\nThe original pull request is %s"
+)
+
+var regCherryPick = regexp.MustCompile(`(?mi)^/cherry-pick\s+(\S+)\s*$`)
+
+func parseCherryPickBranches(comment string) []string {
+	var r []string
+
+	for _, m := range regCherryPick.FindAllStringSubmatch(comment, -1) {
+		r = append(r, m[1])
+	}
+
+	return r
+}
+
+func (bot *robot) handleCherryPick(e *sdk.NoteEvent, cfg *botConfig, log *logrus.Entry) error {
+	ne := giteeclient.NewPRNoteEvent(e)
+
+	if !ne.IsPullRequest() || !ne.IsCreatingCommentEvent() {
+		return nil
+	}
+
+	branches := parseCherryPickBranches(ne.GetComment())
+	if len(branches) == 0 {
+		return nil
+	}
+
+	pr := ne.GetPRInfo()
+	commenter := ne.GetCommenter()
+
+	v, err := bot.hasPermission(commenter, pr, cfg, log)
+	if err != nil {
+		return err
+	}
+
+	if !v {
+		return bot.notifyInStatusComment(ne, cfg, fmt.Sprintf(
+			commentNoPermissionForCmd, commenter, "cherry-pick",
+		))
+	}
+
+	prHook := ne.GetPullRequest()
+	if prHook.State != prStateMerged {
+		return bot.cli.CreatePRComment(pr.Org, pr.Repo, pr.Number, fmt.Sprintf(
+			msgCherryPickAfterMerged, commenter, strings.Join(branches, ", "),
+		))
+	}
+
+	merr := utils.NewMultiErrors()
+	for _, branch := range branches {
+		if err := bot.cherryPick(pr.Org, pr.Repo, prHook, branch, commenter); err != nil {
+			merr.AddError(err)
+		}
+	}
+
+	return merr.Err()
+}
+
+// handleCherryPickAfterMerge does the cherry-picks which are requested before the pr is merged.
+func (bot *robot) handleCherryPickAfterMerge(e *sdk.PullRequestEvent, cfg *botConfig, log *logrus.Entry) error {
+	if e.Action == nil || *e.Action != prActionMerge {
+		return nil
+	}
+
+	pr := giteeclient.GetPRInfoByPREvent(e)
+
+	comments, err := bot.cli.ListPRComments(pr.Org, pr.Repo, pr.Number)
+	if err != nil {
+		return err
+	}
+
+	// the map of branch to the requester
+	requests := map[string]string{}
+	permissions := map[string]bool{}
+
+	for i := range comments {
+		c := &comments[i]
+
+		branches := parseCherryPickBranches(c.Body)
+		if len(branches) == 0 || c.User == nil {
+			continue
+		}
+
+		commenter := c.User.Login
+
+		v, ok := permissions[commenter]
+		if !ok {
+			if v, err = bot.hasPermission(commenter, pr, cfg, log); err != nil {
+				log.WithError(err).Errorf("check the permission of %s", commenter)
+			}
+
+			permissions[commenter] = v
+		}
+
+		if !v {
+			continue
+		}
+
+		for _, branch := range branches {
+			requests[branch] = commenter
+		}
+	}
+
+	merr := utils.NewMultiErrors()
+	for branch, requester := range requests {
+		if err := bot.cherryPick(pr.Org, pr.Repo, e.GetPullRequest(), branch, requester); err != nil {
+			merr.AddError(err)
+		}
+	}
+
+	return merr.Err()
+}
+
+// cherryPick applies the commits of the merged pr to the target branch on a
+// new branch and creates a pull request for it.
+func (bot *robot) cherryPick(org, repo string, pr *sdk.PullRequestHook, branch, requester string) error {
+	number := pr.Number
+
+	// the error is returned to be logged rather than commented, because it may contain
+	// the url of api with the token.
+	commentError := func(err error) error {
+		_ = bot.cli.CreatePRComment(org, repo, number, fmt.Sprintf(
+			msgCherryPickFailed, requester, branch,
+		))
+
+		return err
+	}
+
+	prCommits, err := bot.cli.GetPRCommits(org, repo, number)
+	if err != nil {
+		return commentError(err)
+	}
+
+	commits := make([]string, 0, len(prCommits))
+	for i := range prCommits {
+		commits = append(commits, prCommits[i].Sha)
+	}
+
+	w, err := bot.git.clone(org, repo, branch)
+	if err != nil {
+		return commentError(err)
+	}
+	defer w.clean()
+
+	if err := w.fetch(fmt.Sprintf("refs/pull/%d/head", number)); err != nil {
+		return commentError(err)
+	}
+
+	newBranch := fmt.Sprintf("cherry-pick-%d-to-%s", number, branch)
+	if err := w.checkoutNewBranch(newBranch); err != nil {
+		return commentError(err)
+	}
+
+	ok, err := w.cherryPick(commits)
+	if err != nil {
+		return commentError(err)
+	}
+
+	if !ok {
+		return bot.cli.CreatePRComment(org, repo, number, fmt.Sprintf(
+			msgCherryPickConflicts, requester, branch,
+			number, newBranch, branch, strings.Join(commits, " "),
+		))
+	}
+
+	if err := w.push(newBranch); err != nil {
+		return commentError(err)
+	}
+
+	v, err := bot.cli.CreatePullRequest(
+		org, repo,
+		fmt.Sprintf(cherryPickPRTitle, branch, pr.Title),
+		fmt.Sprintf(cherryPickPRBody, number, branch, pr.HtmlUrl),
+		newBranch, branch, true,
+	)
+	if err != nil {
+		return commentError(err)
+	}
+
+	return bot.cli.CreatePRComment(org, repo, number, fmt.Sprintf(
+		msgCherryPickDone, requester, branch, v.HtmlUrl,
+	))
+}
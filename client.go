@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/opensourceways/community-robot-lib/giteeclient"
+)
+
+const (
+	giteeAPIEndpoint = "https://gitee.com/api/v5"
+
+	// perPage is the max count of items in a page of gitee.
+	perPage = 100
+)
+
+type commitStatus struct {
+	Context     string `json:"context"`
+	State       string `json:"state"`
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url"`
+}
+
+// client extends the gitee client of community-robot-lib with the apis
+// which are not supported by it.
+type client struct {
+	giteeclient.Client
+
+	getToken func() []byte
+	hc       http.Client
+}
+
+func newClient(getToken func() []byte) *client {
+	return &client{
+		Client:   giteeclient.NewClient(getToken),
+		getToken: getToken,
+		hc:       http.Client{Timeout: time.Minute},
+	}
+}
+
+// ListCommitStatuses returns the statuses of the commit, the latest one is the first.
+func (c *client) ListCommitStatuses(org, repo, ref string) ([]commitStatus, error) {
+	var r []commitStatus
+
+	err := c.get(fmt.Sprintf("repos/%s/%s/commits/%s/statuses", org, repo, ref), nil, &r)
+
+	return r, err
+}
+
+// GetOrgIssue returns the issue of org by its number, which is unique in the org.
+// It finds the issue in any repo of org, unlike GetIssue which needs the repo.
+func (c *client) GetOrgIssue(org, number string) (sdk.Issue, error) {
+	var r sdk.Issue
+
+	err := c.get(fmt.Sprintf("repos/%s/issues/%s", org, number), nil, &r)
+
+	return r, err
+}
+
+// ListTeamMembers returns the members of the team of org, which is identified by its slug.
+func (c *client) ListTeamMembers(org, slug string) ([]sdk.UserBasic, error) {
+	var r []sdk.UserBasic
+
+	for page := 1; ; page++ {
+		var v []sdk.UserBasic
+
+		query := url.Values{}
+		query.Set("page", strconv.Itoa(page))
+		query.Set("per_page", strconv.Itoa(perPage))
+
+		if err := c.get(fmt.Sprintf("orgs/%s/teams/%s/members", org, slug), query, &v); err != nil {
+			return nil, err
+		}
+
+		r = append(r, v...)
+
+		if len(v) < perPage {
+			return r, nil
+		}
+	}
+}
+
+func (c *client) get(path string, query url.Values, result interface{}) error {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("access_token", string(c.getToken()))
+
+	resp, err := c.hc.Get(fmt.Sprintf("%s/%s?%s", giteeAPIEndpoint, path, query.Encode()))
+	if err != nil {
+		// the url in the error has the token, so drop its query.
+		if v, ok := err.(*url.Error); ok {
+			v.URL = fmt.Sprintf("%s/%s", giteeAPIEndpoint, path)
+		}
+
+		return err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("request %s failed, status code:%d, body:%s", path, resp.StatusCode, string(b))
+	}
+
+	return json.Unmarshal(b, result)
+}
@@ -86,12 +86,65 @@ type botConfig struct {
 	LabelsForMerge []string `json:"labels_for_merge,omitempty"`
 
 	// MissingLabelsForMerge specifies the ones which a PR must not have to be merged.
+	// The glob pattern of label, such as 'do-not-merge/*', is supported.
 	MissingLabelsForMerge []string `json:"missing_labels_for_merge,omitempty"`
 
+	// LabelExpressionsForMerge specifies the boolean expressions of labels which
+	// must all be true to merge pr, such as 'ci-success || ci-skipped' or
+	// '!kind/feature || doc-reviewed'. The glob pattern of label is supported.
+	LabelExpressionsForMerge []string    `json:"label_expressions_for_merge,omitempty"`
+	labelExprs               []labelExpr `json:"-"`
+
+	// RequiredStatusChecks specifies the contexts of commit status which must be
+	// successful on the head commit of PR to merge it.
+	RequiredStatusChecks []string `json:"required_status_checks,omitempty"`
+
+	// Size specifies how to compute the size of PR, which is used to apply the size label
+	// and scale the number of lgtm labels required.
+	Size *sizeConfig `json:"size,omitempty"`
+
+	// MaxCommits is the maximum number of commits of PR. The PR which exceeds it will be
+	// labeled with needs-squash and can't be merged unless the merge method is squash.
+	// It is not limited when it is 0.
+	MaxCommits int `json:"max_commits,omitempty"`
+
+	// Lint specifies the rules of linting the title of PR and the messages of commits.
+	// It is a merge condition when it is set.
+	Lint *lintConfig `json:"lint,omitempty"`
+
+	// CheckDCO specifies whether every commit of PR must be signed off by its author.
+	// It is a merge condition when it is true.
+	CheckDCO bool `json:"check_dco,omitempty"`
+
+	// AllowedTargetBranches are the glob patterns of branches which PR can be merged into.
+	// Any branch is allowed when it is empty.
+	AllowedTargetBranches []string `json:"allowed_target_branches,omitempty"`
+
+	// EOLBranches are the glob patterns of branches which have reached their end of life.
+	// PR can't be merged into them even if they are allowed.
+	EOLBranches []string `json:"eol_branches,omitempty"`
+
+	// BackportApprovals specifies the target branches whose PR must be approved by
+	// the release managers with the command of /backport-approve.
+	BackportApprovals []backportApproval `json:"backport_approvals,omitempty"`
+
+	// RequireLinkedIssue specifies whether PR must refer to an open issue in its title or body,
+	// such as 'Fixes #I4ABCD'. It is a merge condition when it is true, and the issues
+	// referred will be closed after the PR is merged by robot.
+	RequireLinkedIssue bool `json:"require_linked_issue,omitempty"`
+
+	// MergeWindows specifies when PR can be merged automatically. The PR which is
+	// ready to merge will be queued and merged when the window opens.
+	MergeWindows *mergeWindows `json:"merge_windows,omitempty"`
+
 	// MergeMethod is the method to merge PR.
 	// The default method of merge. Valid options are squash and merge.
 	MergeMethod pullRequestMergeMethod `json:"merge_method,omitempty"`
 
+	// PruneSourceBranch specifies whether to delete the source branch of PR after it is merged.
+	// The source branch will not be deleted if it is protected or belongs to a fork.
+	PruneSourceBranch bool `json:"prune_source_branch,omitempty"`
+
 	// UnableCheckingReviewerForPR is a switch used to check whether the pr has been set reviewers when it is open.
 	UnableCheckingReviewerForPR bool `json:"unable_checking_reviewer_for_pr,omitempty"`
 
@@ -107,6 +160,10 @@ func (c *botConfig) setDefault() {
 	if c.MergeMethod == "" {
 		c.MergeMethod = mergeMethodeMerge
 	}
+
+	for i := range c.BackportApprovals {
+		c.BackportApprovals[i].setDefault()
+	}
 }
 
 func (c *botConfig) validate() error {
@@ -130,8 +187,62 @@ func (c *botConfig) validate() error {
 		c.regSigDir = *v
 	}
 
+	for _, p := range c.MissingLabelsForMerge {
+		if err := validatePattern(p); err != nil {
+			return fmt.Errorf("invalid label pattern: %s", p)
+		}
+	}
+
+	c.labelExprs = make([]labelExpr, 0, len(c.LabelExpressionsForMerge))
+	for _, s := range c.LabelExpressionsForMerge {
+		e, err := parseLabelExpr(s)
+		if err != nil {
+			return err
+		}
+
+		c.labelExprs = append(c.labelExprs, e)
+	}
+
+	if c.Size != nil {
+		if err := c.Size.validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, v := range [][]string{c.AllowedTargetBranches, c.EOLBranches} {
+		for _, p := range v {
+			if err := validatePattern(p); err != nil {
+				return fmt.Errorf("invalid branch pattern: %s", p)
+			}
+		}
+	}
+
+	for i := range c.BackportApprovals {
+		if err := c.BackportApprovals[i].validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.MaxCommits < 0 {
+		return fmt.Errorf("max_commits must not be negative")
+	}
+
+	if c.Lint != nil {
+		if err := c.Lint.validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.MergeWindows != nil {
+		if err := c.MergeWindows.validate(); err != nil {
+			return err
+		}
+	}
+
 	for _, v := range c.FreezeFile {
-		return v.validate()
+		if err := v.validate(); err != nil {
+			return err
+		}
 	}
 
 	return c.PluginForRepo.Validate()
@@ -142,6 +253,34 @@ type freezeFile struct {
 	Repo   string `json:"repo" required:"true"`
 	Branch string `json:"branch" required:"true"`
 	Path   string `json:"path" required:"true"`
+
+	// SigsDir is the directory of sigs in the repo of freeze file, which is used to resolve
+	// the owners of freeze such as 'sig:Kernel'. The default is 'sig'.
+	SigsDir string `json:"sigs_dir,omitempty"`
+
+	// OwnersBranch is the branch of the repos referred by the owners of freeze such as
+	// 'repo:openeuler/release-management'. The default is the branch of freeze file.
+	OwnersBranch string `json:"owners_branch,omitempty"`
+}
+
+func (f freezeFile) ownersBranch() string {
+	if f.OwnersBranch == "" {
+		return f.Branch
+	}
+
+	return f.OwnersBranch
+}
+
+func (f freezeFile) sigsDir() string {
+	if f.SigsDir == "" {
+		return defaultSigsDir
+	}
+
+	return f.SigsDir
+}
+
+func (f freezeFile) String() string {
+	return fmt.Sprintf("%s/%s/%s:%s", f.Owner, f.Repo, f.Branch, f.Path)
 }
 
 func (f freezeFile) validate() error {
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/opensourceways/community-robot-lib/giteeclient"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	dcoPassedLabel = "dco-passed"
+	dcoFailedLabel = "dco-failed"
+
+	checkNameDCO = "DCO"
+
+	msgDCOPassed        = "All the commits are signed off by their authors."
+	msgDCOFailed        = "These commits are not signed off by their authors: %s"
+	msgFailedToCheckDCO = "Failed to get the commits of PR."
+	msgDCOFailedComment = `@%s , these commits are not signed off by their authors: %s
+
+Every commit must have a line of ***Signed-off-by: name <email>*** whose email is the same as the author of commit.
+Please sign off them as below and push them again with force:
+` + "```" + `
+# sign off the last commit
+git commit --amend --signoff
+# or sign off the last N commits
+git rebase --signoff HEAD~N
+` + "```"
+)
+
+var regSignedOff = regexp.MustCompile(`(?mi)^\s*Signed-off-by:.*<([^>]+)>\s*$`)
+
+// getUnsignedCommits returns the commits which are not signed off by their authors.
+func getUnsignedCommits(commits []sdk.PullRequestCommits) []string {
+	var r []string
+
+	for i := range commits {
+		c := &commits[i]
+
+		if c.Commit == nil || c.Commit.Author == nil || !isSignedOffBy(c.Commit.Message, c.Commit.Author.Email) {
+			r = append(r, c.Sha)
+		}
+	}
+
+	return r
+}
+
+func isSignedOffBy(message, email string) bool {
+	for _, m := range regSignedOff.FindAllStringSubmatch(message, -1) {
+		if strings.EqualFold(strings.TrimSpace(m[1]), email) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *mergeHelper) checkDCO() mergeCheck {
+	r := mergeCheck{name: checkNameDCO}
+
+	commits, err := m.getCommits()
+	if err != nil {
+		r.detail = msgFailedToCheckDCO
+		r.err = err
+
+		return r
+	}
+
+	if v := getUnsignedCommits(commits); len(v) > 0 {
+		r.detail = fmt.Sprintf(msgDCOFailed, strings.Join(v, ", "))
+	} else {
+		r.passed = true
+		r.detail = msgDCOPassed
+	}
+
+	return r
+}
+
+// handleDCO checks the sign-off of commits when the pr is opened or its commits change.
+func (bot *robot) handleDCO(e *sdk.PullRequestEvent, cfg *botConfig, log *logrus.Entry) error {
+	if !cfg.CheckDCO {
+		return nil
+	}
+
+	if a := giteeclient.GetPullRequestAction(e); a != giteeclient.PRActionOpened &&
+		a != giteeclient.PRActionChangedSourceBranch {
+		return nil
+	}
+
+	pr := giteeclient.GetPRInfoByPREvent(e)
+
+	commits, err := bot.cli.GetPRCommits(pr.Org, pr.Repo, pr.Number)
+	if err != nil {
+		return err
+	}
+
+	unsigned := getUnsignedCommits(commits)
+
+	if len(unsigned) == 0 {
+		return bot.switchLabel(pr, dcoFailedLabel, dcoPassedLabel, log)
+	}
+
+	if err := bot.switchLabel(pr, dcoPassedLabel, dcoFailedLabel, log); err != nil {
+		return err
+	}
+
+	// the author has been told how to fix it when the check failed at first,
+	// so only the status comment is refreshed to list the unsigned commits.
+	if pr.Labels.Has(dcoFailedLabel) {
+		bot.reevaluate(prKey{org: pr.Org, repo: pr.Repo, number: pr.Number}, cfg, "", log)
+
+		return nil
+	}
+
+	return bot.cli.CreatePRComment(pr.Org, pr.Repo, pr.Number, fmt.Sprintf(
+		msgDCOFailedComment, pr.Author, strings.Join(unsigned, ", "),
+	))
+}
+
+// switchLabel replaces the label of from with the label of to on pr if needed.
+func (bot *robot) switchLabel(pr giteeclient.PRInfo, from, to string, log *logrus.Entry) error {
+	labels := pr.Labels
+	if labels == nil {
+		labels = sets.NewString()
+	}
+
+	if labels.Has(from) {
+		if err := bot.cli.RemovePRLabel(pr.Org, pr.Repo, pr.Number, from); err != nil {
+			return err
+		}
+	}
+
+	if labels.Has(to) {
+		return nil
+	}
+
+	if err := bot.createLabelIfNeed(pr.Org, pr.Repo, to); err != nil {
+		log.WithError(err).Errorf("create repo label: %s", to)
+	}
+
+	return bot.cli.AddPRLabel(pr.Org, pr.Repo, pr.Number, to)
+}
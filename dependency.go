@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/opensourceways/community-robot-lib/giteeclient"
+	"github.com/opensourceways/community-robot-lib/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	prStateMerged = "merged"
+	prActionMerge = "merge"
+
+	checkNameDependencies = "Dependencies"
+
+	msgDependenciesMerged    = "All the dependent PRs are merged: %s"
+	msgDependenciesNotMerged = "These dependent PRs are not merged: %s"
+)
+
+var regDependsOn = regexp.MustCompile(`(?mi)^\s*Depends-On:\s*([-\w.]+)/([-\w.]+)#(\d+)\s*$`)
+
+type prKey struct {
+	org    string
+	repo   string
+	number int32
+}
+
+func (k prKey) String() string {
+	return fmt.Sprintf("%s/%s#%d", k.org, k.repo, k.number)
+}
+
+// parseDependencies parses the lines of 'Depends-On: org/repo#123' in the body of PR.
+func parseDependencies(body string) []prKey {
+	var r []prKey
+
+	for _, m := range regDependsOn.FindAllStringSubmatch(body, -1) {
+		n, err := strconv.Atoi(m[3])
+		if err != nil {
+			continue
+		}
+
+		r = append(r, prKey{org: m[1], repo: m[2], number: int32(n)})
+	}
+
+	return r
+}
+
+func (m *mergeHelper) checkDependencies(deps []prKey) mergeCheck {
+	r := mergeCheck{name: checkNameDependencies}
+
+	var unmerged []string
+	merr := utils.NewMultiErrors()
+	for _, dep := range deps {
+		pr, err := m.cli.GetGiteePullRequest(dep.org, dep.repo, dep.number)
+		if err != nil {
+			unmerged = append(unmerged, fmt.Sprintf("%s(failed to get it)", dep))
+			merr.AddError(err)
+
+			continue
+		}
+
+		if pr.State != prStateMerged {
+			unmerged = append(unmerged, fmt.Sprintf("%s(%s)", dep, pr.State))
+		}
+	}
+
+	if len(unmerged) > 0 {
+		r.detail = fmt.Sprintf(msgDependenciesNotMerged, strings.Join(unmerged, ", "))
+		r.err = merr.Err()
+	} else {
+		r.passed = true
+		r.detail = fmt.Sprintf(msgDependenciesMerged, joinPRKeys(deps))
+	}
+
+	return r
+}
+
+func joinPRKeys(keys []prKey) string {
+	s := make([]string, len(keys))
+	for i := range keys {
+		s[i] = keys[i].String()
+	}
+
+	return strings.Join(s, ", ")
+}
+
+// dependencyIndex records the PRs which are blocked by their dependencies,
+// so that they can be re-evaluated when the dependency is merged.
+type dependencyIndex struct {
+	lock sync.Mutex
+
+	// dependents is the map from the dependency to the PRs depending on it.
+	dependents map[prKey]map[prKey]*botConfig
+
+	// dependencies is the map from the PR to the ones it depends on, which is the reverse of dependents.
+	dependencies map[prKey][]prKey
+}
+
+func newDependencyIndex() *dependencyIndex {
+	return &dependencyIndex{
+		dependents:   map[prKey]map[prKey]*botConfig{},
+		dependencies: map[prKey][]prKey{},
+	}
+}
+
+// add records the dependencies of pr, which replace the ones recorded before.
+func (d *dependencyIndex) add(dependent prKey, deps []prKey, cfg *botConfig) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.removeDependent(dependent)
+
+	for _, dep := range deps {
+		v, ok := d.dependents[dep]
+		if !ok {
+			v = map[prKey]*botConfig{}
+			d.dependents[dep] = v
+		}
+
+		v[dependent] = cfg
+	}
+
+	d.dependencies[dependent] = deps
+}
+
+// pop removes the pr from the index and returns the PRs depending on it. They are
+// removed too, and will be added again if they are still blocked after re-evaluation.
+func (d *dependencyIndex) pop(k prKey) map[prKey]*botConfig {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	v := d.dependents[k]
+	delete(d.dependents, k)
+
+	d.removeDependent(k)
+	for dependent := range v {
+		d.removeDependent(dependent)
+	}
+
+	return v
+}
+
+// removeDependent must be called with the lock held.
+func (d *dependencyIndex) removeDependent(dependent prKey) {
+	for _, dep := range d.dependencies[dependent] {
+		if v, ok := d.dependents[dep]; ok {
+			delete(v, dependent)
+
+			if len(v) == 0 {
+				delete(d.dependents, dep)
+			}
+		}
+	}
+
+	delete(d.dependencies, dependent)
+}
+
+func (bot *robot) watchDependencies(h *mergeHelper, checks []mergeCheck) {
+	for i := range checks {
+		if item := &checks[i]; item.name == checkNameDependencies && !item.passed {
+			bot.dependencies.add(
+				prKey{org: h.org, repo: h.repo, number: h.pr.Number},
+				parseDependencies(h.pr.Body), h.cfg,
+			)
+
+			return
+		}
+	}
+}
+
+// handleDependencyMerged re-evaluates the PRs depending on the merged one.
+// The closed or merged PR is removed from the index of dependencies.
+func (bot *robot) handleDependencyMerged(e *sdk.PullRequestEvent, log *logrus.Entry) error {
+	merged := e.Action != nil && *e.Action == prActionMerge
+	if !merged && giteeclient.GetPullRequestAction(e) != giteeclient.PRActionClosed {
+		return nil
+	}
+
+	pr := giteeclient.GetPRInfoByPREvent(e)
+
+	dependents := bot.dependencies.pop(prKey{org: pr.Org, repo: pr.Repo, number: pr.Number})
+	if !merged {
+		return nil
+	}
+
+	for k, cfg := range dependents {
+		bot.reevaluate(k, cfg, "", log)
+	}
+
+	return nil
+}
+
+// reevaluate fetches the latest state of the pr, then evaluates and merges it if it is ready.
+func (bot *robot) reevaluate(k prKey, cfg *botConfig, trigger string, log *logrus.Entry) {
+	bot.evaluateQueued(queuedMerge{Org: k.org, Repo: k.repo, Number: k.number, Trigger: trigger}, cfg, log)
+}
+
+// evaluateQueued is reevaluate of the pr in the merge queue, which keeps the state of waiting.
+func (bot *robot) evaluateQueued(item queuedMerge, cfg *botConfig, log *logrus.Entry) {
+	k := item.key()
+
+	pr, err := bot.cli.GetGiteePullRequest(k.org, k.repo, k.number)
+	if err != nil {
+		log.WithError(err).Errorf("get pr %s", k)
+
+		return
+	}
+
+	if pr.State != prStateOpen {
+		return
+	}
+
+	h := mergeHelper{
+		cfg:          cfg,
+		org:          k.org,
+		repo:         k.repo,
+		cli:          bot.cli,
+		cacheCli:     bot.cacheCli,
+		botLogin:     bot.botLogin,
+		freeze:       bot.freezeCache,
+		teams:        bot.teamCache,
+		pr:           newPRHook(&pr),
+		trigger:      item.Trigger,
+		waitingSince: item.WaitingSince,
+	}
+
+	if err := bot.mergeOrReport(&h, "", log); err != nil {
+		log.WithError(err).Errorf("merge pr %s", k)
+	}
+}
@@ -1,46 +1,196 @@
 package main
 
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	freezeTimeFormat = "2006-01-02 15:04"
+
+	// exactMatchSpecificity is the specificity of the pattern without wildcards,
+	// which is more specific than any glob pattern.
+	exactMatchSpecificity = 1 << 16
+)
+
 type freezeContent struct {
 	Release []freezeItem `json:"release"`
 }
 
-func (fc freezeContent) getFreezeItem(org, branch string) *freezeItem {
-	for _, v := range fc.Release {
-		if v.Branch == branch && v.hasOrg(org) {
-			return &v
+// freezeMatch is the specificity of the branch and community of freeze item matched.
+// The branch is compared first and the community next.
+type freezeMatch struct {
+	branch    int
+	community int
+}
+
+func (fm freezeMatch) moreSpecificThan(other freezeMatch) bool {
+	if fm.branch != other.branch {
+		return fm.branch > other.branch
+	}
+
+	return fm.community > other.community
+}
+
+// getFreezeItem returns the most specific freeze item matching the org and branch.
+// The first one wins if there are several items with the same specificity.
+func (fc freezeContent) getFreezeItem(org, branch string) (*freezeItem, freezeMatch) {
+	var r *freezeItem
+	var rm freezeMatch
+
+	for i := range fc.Release {
+		v := &fc.Release[i]
+
+		bs, ok := matchSpecificity(v.Branch, branch)
+		if !ok {
+			continue
+		}
+
+		cs, ok := v.matchOrg(org)
+		if !ok {
+			continue
+		}
+
+		if m := (freezeMatch{branch: bs, community: cs}); r == nil || m.moreSpecificThan(rm) {
+			r, rm = v, m
 		}
 	}
 
-	return nil
+	return r, rm
+}
+
+// matchSpecificity matches s with the glob pattern, such as 'openEuler-22.03-LTS*',
+// and returns the specificity which is the count of characters except wildcards.
+func matchSpecificity(pattern, s string) (int, bool) {
+	if pattern == s {
+		return exactMatchSpecificity, true
+	}
+
+	if ok, _ := path.Match(pattern, s); !ok {
+		return 0, false
+	}
+
+	return len(strings.Map(func(r rune) rune {
+		if r == '*' || r == '?' {
+			return -1
+		}
+
+		return r
+	}, pattern)), true
 }
 
 type freezeItem struct {
-	Branch    string   `json:"branch"`
+	// Branch is the name or glob pattern of branch, such as 'openEuler-22.03-LTS*'.
+	Branch string `json:"branch"`
+
+	// Community are the names or glob patterns of orgs, such as '*' which matches every org.
 	Community []string `json:"community"`
-	Frozen    bool     `json:"frozen"`
-	Owner     []string `json:"owner"`
+
+	Frozen bool     `json:"frozen"`
+	Owner  []string `json:"owner"`
+
+	// FreezeStart and FreezeEnd are the time when the freeze starts and ends, such as
+	// '2022-03-01 08:00' or '2022-03-01T08:00:00+08:00'. The branch is frozen from the
+	// start(inclusive) to the end(exclusive) and either of them can be omitted.
+	// Frozen overrides them when it is true.
+	FreezeStart string `json:"freeze_start,omitempty"`
+	FreezeEnd   string `json:"freeze_end,omitempty"`
+
+	// Timezone is the IANA name of timezone of FreezeStart and FreezeEnd, such as 'Asia/Shanghai'.
+	// The default is UTC.
+	Timezone string `json:"timezone,omitempty"`
+
+	start time.Time
+	end   time.Time
+}
+
+// parseSchedule parses the start and end of freeze.
+func (fi *freezeItem) parseSchedule() error {
+	if fi.FreezeStart == "" && fi.FreezeEnd == "" {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(fi.Timezone)
+	if err != nil {
+		return fmt.Errorf("invalid timezone of freeze: %s", err.Error())
+	}
+
+	if fi.start, err = parseFreezeTime(fi.FreezeStart, loc); err != nil {
+		return fmt.Errorf("invalid freeze_start: %s", err.Error())
+	}
+
+	if fi.end, err = parseFreezeTime(fi.FreezeEnd, loc); err != nil {
+		return fmt.Errorf("invalid freeze_end: %s", err.Error())
+	}
+
+	if !fi.start.IsZero() && !fi.end.IsZero() && !fi.start.Before(fi.end) {
+		return fmt.Errorf("freeze_start must be before freeze_end")
+	}
+
+	return nil
+}
+
+func parseFreezeTime(s string, loc *time.Location) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	return time.ParseInLocation(freezeTimeFormat, s, loc)
+}
+
+func (fi freezeItem) hasSchedule() bool {
+	return !fi.start.IsZero() || !fi.end.IsZero()
 }
 
 func (fi freezeItem) isFrozen() bool {
-	return fi.Frozen
+	return fi.isFrozenAt(time.Now())
 }
 
-func (fi freezeItem) hasOrg(org string) bool {
-	for _, v := range fi.Community {
-		if v == org {
-			return true
-		}
+func (fi freezeItem) isFrozenAt(now time.Time) bool {
+	if fi.Frozen {
+		return true
+	}
+
+	if !fi.hasSchedule() {
+		return false
+	}
+
+	return (fi.start.IsZero() || !now.Before(fi.start)) && (fi.end.IsZero() || now.Before(fi.end))
+}
+
+// nextFreeze returns the start of freeze if it is scheduled after now.
+func (fi freezeItem) nextFreeze(now time.Time) (time.Time, bool) {
+	if fi.Frozen || fi.start.IsZero() || !now.Before(fi.start) {
+		return time.Time{}, false
 	}
 
-	return false
+	return fi.start, true
+}
+
+func (fi freezeItem) describe() string {
+	return fmt.Sprintf("branch `%s` of communities `%s`", fi.Branch, strings.Join(fi.Community, ", "))
 }
 
-func (fi freezeItem) isOwner(owner string) bool {
-	for _, v := range fi.Owner {
-		if v == owner {
-			return false
+// key identifies the item by its branch and communities.
+func (fi freezeItem) key() string {
+	return fi.Branch + "@" + strings.Join(fi.Community, ",")
+}
+
+// matchOrg returns the highest specificity of communities which match the org.
+func (fi freezeItem) matchOrg(org string) (int, bool) {
+	r, matched := 0, false
+
+	for _, v := range fi.Community {
+		if n, ok := matchSpecificity(v, org); ok && (!matched || n > r) {
+			r, matched = n, true
 		}
 	}
 
-	return fi.isFrozen()
+	return r, matched
 }
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/opensourceways/community-robot-lib/giteeclient"
+	"github.com/opensourceways/community-robot-lib/utils"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	branchFrozenLabel = "branch-frozen"
+
+	commentBranchFrozen = `@%s , the target branch ***%s*** of this pull request has been frozen by %s.
+It can be merged only by the branch owners: %s, or after its freeze exception is approved by ***/freeze-exception***.`
+	commentBranchUnfrozen = "@%s , the target branch ***%s*** of this pull request is not frozen any more, so its merge will be re-attempted."
+
+	// freezeSyncRequestInterval limits the rate of the requests to gitee when the freeze
+	// states are synced, because a change of freeze may affect many repos and prs.
+	freezeSyncRequestInterval = 200 * time.Millisecond
+)
+
+// handleFreezeFilePush refreshes the freeze files when the branch where they are stored is
+// pushed, and asks the freeze watcher to notify the open prs whose target branch is frozen or
+// unfrozen. The notification is sent off the webhook path, because it visits many repos.
+func (bot *robot) handleFreezeFilePush(e *sdk.PushEvent, c *configuration, log *logrus.Entry) error {
+	org, repo := giteeclient.GetOwnerAndRepoByPushEvent(e)
+	branch := strings.TrimPrefix(e.GetRef(), "refs/heads/")
+
+	if len(c.getFreezeFiles(org, repo, branch)) == 0 {
+		return nil
+	}
+
+	bot.freezeCache.invalidate(org, repo, branch)
+
+	select {
+	case bot.freezeSignal <- struct{}{}:
+	default:
+		// a sync is pending already.
+	}
+
+	return nil
+}
+
+// watchFreeze syncs the freeze states of all the freeze files whenever one of them is pushed,
+// and periodically to catch the freeze which starts or ends at the scheduled time.
+func (bot *robot) watchFreeze(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-bot.freezeSignal:
+		case <-t.C:
+		}
+
+		bot.syncFreezeStates(logrus.WithField("job", "sync freeze"))
+	}
+}
+
+// syncFreezeStates compares the freeze files with the ones synced last time, and notifies
+// the open prs whose target branch matches the items changed.
+func (bot *robot) syncFreezeStates(log *logrus.Entry) {
+	c, ok := bot.config.Load().(*configuration)
+	if !ok {
+		// no config is received since the bot starts.
+		return
+	}
+
+	now := time.Now()
+	limiter := &rateLimiter{interval: freezeSyncRequestInterval}
+
+	for _, f := range c.getFreezeFiles("", "", "") {
+		fc, err := bot.freezeCache.get(f, bot.cli)
+		if err != nil {
+			log.WithError(err).Errorf("get freeze file %s", f.String())
+
+			continue
+		}
+
+		if changed := bot.freezeCache.diff(f, fc, now); len(changed) > 0 {
+			if err := bot.broadcastFreeze(f, changed, c, limiter, log); err != nil {
+				log.WithError(err).Errorf("broadcast the freeze of %s", f.String())
+			}
+		}
+	}
+}
+
+// getFreezeFiles returns the freeze files stored in the branch of repo, or all of them if org is empty.
+func (c *configuration) getFreezeFiles(org, repo, branch string) []freezeFile {
+	var r []freezeFile
+
+	v := map[freezeFile]bool{}
+	for i := range c.ConfigItems {
+		for _, f := range c.ConfigItems[i].FreezeFile {
+			if v[f] || (org != "" && (f.Owner != org || f.Repo != repo || f.Branch != branch)) {
+				continue
+			}
+
+			v[f] = true
+			r = append(r, f)
+		}
+	}
+
+	return r
+}
+
+// rateLimiter spaces the calls of wait by the interval. It is used by one goroutine only.
+type rateLimiter struct {
+	interval time.Duration
+	last     time.Time
+}
+
+func (l *rateLimiter) wait() {
+	if d := l.interval - time.Since(l.last); d > 0 {
+		time.Sleep(d)
+	}
+
+	l.last = time.Now()
+}
+
+// broadcastFreeze syncs the branch-frozen label of the open prs in the repos which use
+// the freeze file and whose target branch matches the items changed.
+func (bot *robot) broadcastFreeze(
+	f freezeFile, changed []freezeItemState, c *configuration, limiter *rateLimiter, log *logrus.Entry,
+) error {
+	merr := utils.NewMultiErrors()
+
+	for i := range c.ConfigItems {
+		cfg := &c.ConfigItems[i]
+		if !cfg.hasFreezeFile(f) {
+			continue
+		}
+
+		for _, k := range listConfiguredRepos(cfg, c) {
+			items := matchedFreezeItems(changed, k.org)
+			if len(items) == 0 {
+				continue
+			}
+
+			if err := bot.syncFrozenPRsOfRepo(k.org, k.repo, items, cfg, limiter, log); err != nil {
+				merr.AddError(err)
+			}
+		}
+	}
+
+	return merr.Err()
+}
+
+func (c *botConfig) hasFreezeFile(f freezeFile) bool {
+	for _, v := range c.FreezeFile {
+		if v == f {
+			return true
+		}
+	}
+
+	return false
+}
+
+// listConfiguredRepos returns the repos configured explicitly as org/repo by the config.
+// The repos of an org configured as a whole are not listed, because an org may have
+// thousands of repos. Their prs are still checked against the freeze when they are merged.
+func listConfiguredRepos(cfg *botConfig, c *configuration) []prKey {
+	var r []prKey
+
+	repos, _ := cfg.RepoFilter()
+	for _, item := range repos {
+		if v := strings.Split(item, "/"); len(v) == 2 && c.configFor(v[0], v[1]) == cfg {
+			r = append(r, prKey{org: v[0], repo: v[1]})
+		}
+	}
+
+	return r
+}
+
+func matchedFreezeItems(changed []freezeItemState, org string) []freezeItemState {
+	var r []freezeItemState
+
+	for i := range changed {
+		if _, ok := changed[i].item.matchOrg(org); ok {
+			r = append(r, changed[i])
+		}
+	}
+
+	return r
+}
+
+// syncFrozenPRsOfRepo syncs the branch-frozen label of the open prs whose target branch matches
+// the items changed. Only the prs of the branches changed are listed if they are not patterns.
+func (bot *robot) syncFrozenPRsOfRepo(
+	org, repo string, items []freezeItemState, cfg *botConfig, limiter *rateLimiter, log *logrus.Entry,
+) error {
+	merr := utils.NewMultiErrors()
+
+	for _, base := range listedBaseBranches(items) {
+		limiter.wait()
+
+		prs, err := bot.cli.GetPullRequests(org, repo, giteeclient.ListPullRequestOpt{State: prStateOpen, Base: base})
+		if err != nil {
+			merr.AddError(err)
+
+			continue
+		}
+
+		for i := range prs {
+			pr := &prs[i]
+			if pr.Base == nil || !matchesFreezeItems(items, pr.Base.Ref) {
+				continue
+			}
+
+			limiter.wait()
+
+			if err := bot.syncBranchFrozenLabel(org, repo, pr, cfg, log); err != nil {
+				merr.AddError(err)
+			}
+		}
+	}
+
+	return merr.Err()
+}
+
+// listedBaseBranches returns the target branches to list the prs by. It is a single empty
+// branch which lists the prs of all branches if any item is a glob pattern of branch.
+func listedBaseBranches(items []freezeItemState) []string {
+	v := sets.NewString()
+
+	for i := range items {
+		b := items[i].item.Branch
+		if strings.ContainsAny(b, `*?[\`) {
+			return []string{""}
+		}
+
+		v.Insert(b)
+	}
+
+	return v.List()
+}
+
+func matchesFreezeItems(items []freezeItemState, branch string) bool {
+	for i := range items {
+		if _, ok := matchSpecificity(items[i].item.Branch, branch); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// syncBranchFrozenLabel applies the branch-frozen label by the freeze rule which takes effect
+// on the pr, because the item changed may be overridden by a more specific one.
+func (bot *robot) syncBranchFrozenLabel(org, repo string, pr *sdk.PullRequest, cfg *botConfig, log *logrus.Entry) error {
+	h := mergeHelper{
+		cfg:      cfg,
+		org:      org,
+		repo:     repo,
+		cli:      bot.cli,
+		cacheCli: bot.cacheCli,
+		botLogin: bot.botLogin,
+		freeze:   bot.freezeCache,
+		teams:    bot.teamCache,
+		pr:       newPRHook(pr),
+	}
+
+	rule, err := h.getFreezeInfo()
+	if err != nil {
+		return err
+	}
+
+	labels := sets.NewString()
+	for _, l := range pr.Labels {
+		labels.Insert(l.Name)
+	}
+
+	author := ""
+	if pr.User != nil {
+		author = pr.User.Login
+	}
+
+	frozen := rule != nil && rule.isFrozen()
+	hasLabel := labels.Has(branchFrozenLabel)
+
+	if frozen && !hasLabel {
+		if err := bot.createLabelIfNeed(org, repo, branchFrozenLabel); err != nil {
+			log.WithError(err).Errorf("create repo label: %s", branchFrozenLabel)
+		}
+
+		if err := bot.cli.AddPRLabel(org, repo, pr.Number, branchFrozenLabel); err != nil {
+			return err
+		}
+
+		return bot.cli.CreatePRComment(org, repo, pr.Number, fmt.Sprintf(
+			commentBranchFrozen, author, pr.Base.Ref, rule.String(), strings.Join(rule.Owner, ", "),
+		))
+	}
+
+	if !frozen && hasLabel {
+		if err := bot.cli.RemovePRLabel(org, repo, pr.Number, branchFrozenLabel); err != nil {
+			return err
+		}
+
+		if err := bot.cli.CreatePRComment(org, repo, pr.Number, fmt.Sprintf(
+			commentBranchUnfrozen, author, pr.Base.Ref,
+		)); err != nil {
+			log.WithError(err).Errorf("comment on pr %s/%s#%d", org, repo, pr.Number)
+		}
+
+		bot.reevaluate(prKey{org: org, repo: repo, number: pr.Number}, cfg, "", log)
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestListedBaseBranches(t *testing.T) {
+	cases := []struct {
+		branches []string
+		want     []string
+	}{
+		{[]string{"master"}, []string{"master"}},
+		{[]string{"openEuler-22.03-LTS", "master", "master"}, []string{"master", "openEuler-22.03-LTS"}},
+		{[]string{"master", "openEuler-22.03-LTS*"}, []string{""}},
+		{[]string{"openEuler-2[0-2].03-LTS"}, []string{""}},
+	}
+
+	for _, c := range cases {
+		items := make([]freezeItemState, len(c.branches))
+		for i, b := range c.branches {
+			items[i].item.Branch = b
+		}
+
+		if v := listedBaseBranches(items); !reflect.DeepEqual(v, c.want) {
+			t.Errorf("branches %v: got %q, want %q", c.branches, v, c.want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/base64"
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
+)
+
+// freezeFileMetrics counts the loads of freeze files. It is published at /debug/vars.
+var freezeFileMetrics = expvar.NewMap("freeze_file")
+
+const (
+	metricCacheHit   = "cache_hit"
+	metricFetch      = "fetch"
+	metricFetchError = "fetch_error"
+	metricParseError = "parse_error"
+)
+
+type freezeCacheItem struct {
+	content freezeContent
+	expiry  time.Time
+}
+
+// freezeCache caches the content of freeze files until it expires or
+// the branch where the file is stored is pushed.
+type freezeCache struct {
+	lock  sync.RWMutex
+	ttl   time.Duration
+	items map[freezeFile]freezeCacheItem
+
+	// lastSeen is the freeze state of every item of freeze file when it was synced last time.
+	// It is persisted in the store, so the prs are not notified again after the bot restarts.
+	lastSeen map[freezeFile]map[string]freezeItemState
+	store    *stateStore
+}
+
+type freezeItemState struct {
+	item   freezeItem
+	frozen bool
+}
+
+const stateFreeze = "freeze"
+
+// savedFreezeStates is the form of lastSeen of one freeze file in the store.
+type savedFreezeStates struct {
+	File  freezeFile                 `json:"file"`
+	Items map[string]savedFreezeItem `json:"items"`
+}
+
+type savedFreezeItem struct {
+	Item   freezeItem `json:"item"`
+	Frozen bool       `json:"frozen"`
+}
+
+func newFreezeCache(ttl time.Duration, store *stateStore) *freezeCache {
+	c := &freezeCache{
+		ttl:      ttl,
+		items:    make(map[freezeFile]freezeCacheItem),
+		lastSeen: make(map[freezeFile]map[string]freezeItemState),
+		store:    store,
+	}
+
+	var saved []savedFreezeStates
+	if err := store.load(stateFreeze, &saved); err != nil {
+		logrus.WithError(err).Error("load the freeze states")
+	}
+
+	for i := range saved {
+		v := make(map[string]freezeItemState, len(saved[i].Items))
+		for k, item := range saved[i].Items {
+			v[k] = freezeItemState{item: item.Item, frozen: item.Frozen}
+		}
+
+		c.lastSeen[saved[i].File] = v
+	}
+
+	return c
+}
+
+func (c *freezeCache) get(f freezeFile, cli iClient) (freezeContent, error) {
+	now := time.Now()
+
+	c.lock.RLock()
+	item, ok := c.items[f]
+	c.lock.RUnlock()
+
+	if ok && now.Before(item.expiry) {
+		freezeFileMetrics.Add(metricCacheHit, 1)
+
+		return item.content, nil
+	}
+
+	fc, err := loadFreezeFile(f, cli)
+	if err != nil {
+		return fc, err
+	}
+
+	c.lock.Lock()
+	c.items[f] = freezeCacheItem{content: fc, expiry: now.Add(c.ttl)}
+	c.lock.Unlock()
+
+	return fc, nil
+}
+
+// invalidate removes the cached freeze files which are stored in the branch of repo.
+func (c *freezeCache) invalidate(org, repo, branch string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for f := range c.items {
+		if f.Owner == org && f.Repo == repo && f.Branch == branch {
+			delete(c.items, f)
+		}
+	}
+}
+
+// diff returns the items of freeze file whose freeze state is changed since it
+// was synced last time. Every frozen item is changed if it is seen for the first time.
+func (c *freezeCache) diff(f freezeFile, fc freezeContent, now time.Time) []freezeItemState {
+	current := make(map[string]freezeItemState, len(fc.Release))
+
+	for i := range fc.Release {
+		item := fc.Release[i]
+		if err := item.parseSchedule(); err != nil {
+			logrus.WithError(err).Errorf("invalid freeze of branch %s in %s", item.Branch, f.String())
+
+			continue
+		}
+
+		current[item.key()] = freezeItemState{item: item, frozen: item.isFrozenAt(now)}
+	}
+
+	c.lock.Lock()
+	last := c.lastSeen[f]
+	c.lastSeen[f] = current
+	c.saveLastSeen()
+	c.lock.Unlock()
+
+	var r []freezeItemState
+	for k, v := range current {
+		if v.frozen != last[k].frozen {
+			r = append(r, v)
+		}
+	}
+
+	for k, v := range last {
+		if _, ok := current[k]; !ok && v.frozen {
+			r = append(r, freezeItemState{item: v.item})
+		}
+	}
+
+	return r
+}
+
+// saveLastSeen must be called with the lock held.
+func (c *freezeCache) saveLastSeen() {
+	saved := make([]savedFreezeStates, 0, len(c.lastSeen))
+	for f, states := range c.lastSeen {
+		v := savedFreezeStates{File: f, Items: make(map[string]savedFreezeItem, len(states))}
+		for k, item := range states {
+			v.Items[k] = savedFreezeItem{Item: item.item, Frozen: item.frozen}
+		}
+
+		saved = append(saved, v)
+	}
+
+	if err := c.store.save(stateFreeze, saved); err != nil {
+		logrus.WithError(err).Error("save the freeze states")
+	}
+}
+
+func loadFreezeFile(f freezeFile, cli iClient) (freezeContent, error) {
+	var fc freezeContent
+
+	freezeFileMetrics.Add(metricFetch, 1)
+
+	log := logrus.WithField("freeze_file", f.String())
+
+	c, err := cli.GetPathContent(f.Owner, f.Repo, f.Path, f.Branch)
+	if err != nil {
+		freezeFileMetrics.Add(metricFetchError, 1)
+		log.WithError(err).Error("fetch freeze file")
+
+		return fc, err
+	}
+
+	b, err := base64.StdEncoding.DecodeString(c.Content)
+	if err == nil {
+		err = yaml.Unmarshal(b, &fc)
+	}
+
+	if err != nil {
+		freezeFileMetrics.Add(metricParseError, 1)
+		log.WithError(err).Error("parse freeze file")
+
+		return fc, fmt.Errorf("failed to parse the freeze file of %s, %s", f.String(), err.Error())
+	}
+
+	return fc, nil
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/opensourceways/community-robot-lib/giteeclient"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	freezeExceptionRequestedLabel = "freeze-exception-requested"
+	freezeExceptionApprovedLabel  = "freeze-exception-approved"
+
+	freezeExceptionApprove = "approve"
+	freezeExceptionReject  = "reject"
+
+	msgFreezeExceptionApproved = "The target branch of PR has been frozen, but the freeze exception is approved."
+
+	commentNotFrozen               = "@%s , the target branch of this pull request is not frozen, so the freeze exception is not needed."
+	commentFreezeExceptionUsage    = "@%s , please request the freeze exception with a reason, such as `/freeze-exception fix CVE-2022-1234`."
+	commentFreezeExceptionAsked    = "@%s requests a freeze exception for the reason: %s\n\nThe branch owners: %s can approve it by `/freeze-exception approve` or reject it by `/freeze-exception reject`."
+	commentFreezeExceptionNotAsked = "@%s , there is no request of freeze exception on this pull request."
+	commentFreezeExceptionApproved = "The freeze exception into the branch ***%s*** is approved by @%s."
+	commentFreezeExceptionRejected = "The freeze exception is rejected by @%s."
+	commentNotFreezeOwner          = "@%s , only the branch owners: %s can %s the freeze exception."
+	commentFreezeExceptionRevoked  = "@%s , the freeze exception is revoked because the branches of this pull request are changed, please request it again by `/freeze-exception`."
+)
+
+var regFreezeException = regexp.MustCompile(`(?mi)^/freeze-exception(?:\s+(.*\S))?\s*$`)
+
+// handleFreezeException handles the command of /freeze-exception. Anyone can request
+// the exception with a reason, and the owners of the frozen branch can approve or reject it.
+func (bot *robot) handleFreezeException(e *sdk.NoteEvent, cfg *botConfig, log *logrus.Entry) error {
+	ne := giteeclient.NewPRNoteEvent(e)
+
+	if !ne.IsPullRequest() ||
+		!ne.IsPROpen() ||
+		!ne.IsCreatingCommentEvent() ||
+		!regFreezeException.MatchString(ne.GetComment()) {
+		return nil
+	}
+
+	org, repo := ne.GetOrgRep()
+	pr := ne.GetPRInfo()
+	commenter := ne.GetCommenter()
+
+	arg := regFreezeException.FindStringSubmatch(ne.GetComment())[1]
+	if arg == "" {
+		return bot.cli.CreatePRComment(org, repo, pr.Number, fmt.Sprintf(commentFreezeExceptionUsage, commenter))
+	}
+
+	h := mergeHelper{
+		cfg:      cfg,
+		org:      org,
+		repo:     repo,
+		cli:      bot.cli,
+		cacheCli: bot.cacheCli,
+		botLogin: bot.botLogin,
+		freeze:   bot.freezeCache,
+		teams:    bot.teamCache,
+		pr:       ne.GetPullRequest(),
+	}
+
+	freeze, err := h.getFreezeInfo()
+	if err != nil {
+		return err
+	}
+
+	if freeze == nil || !freeze.isFrozen() {
+		return bot.cli.CreatePRComment(org, repo, pr.Number, fmt.Sprintf(commentNotFrozen, commenter))
+	}
+
+	switch strings.ToLower(arg) {
+	case freezeExceptionApprove, freezeExceptionReject:
+		return bot.decideFreezeException(pr, freeze, commenter, strings.ToLower(arg), log)
+
+	default:
+		if err := bot.switchLabel(pr, freezeExceptionApprovedLabel, freezeExceptionRequestedLabel, log); err != nil {
+			return err
+		}
+
+		return bot.cli.CreatePRComment(org, repo, pr.Number, fmt.Sprintf(
+			commentFreezeExceptionAsked, commenter, arg, strings.Join(freeze.Owner, ", "),
+		))
+	}
+}
+
+func (bot *robot) decideFreezeException(
+	pr giteeclient.PRInfo, freeze *freezeRule, commenter, decision string, log *logrus.Entry,
+) error {
+	isOwner, err := freeze.isOwner(bot.ownerSources(), commenter)
+	if err != nil {
+		log.WithError(err).Error("resolve the owners of freeze")
+	}
+
+	if !isOwner {
+		return bot.cli.CreatePRComment(pr.Org, pr.Repo, pr.Number, fmt.Sprintf(
+			commentNotFreezeOwner, commenter, strings.Join(freeze.Owner, ", "), decision,
+		))
+	}
+
+	if !pr.Labels.Has(freezeExceptionRequestedLabel) {
+		return bot.cli.CreatePRComment(pr.Org, pr.Repo, pr.Number, fmt.Sprintf(
+			commentFreezeExceptionNotAsked, commenter,
+		))
+	}
+
+	if decision == freezeExceptionApprove {
+		// comment before adding the label, because the label is trusted only
+		// with the comment when the pr is re-evaluated on the label update.
+		if err := bot.cli.CreatePRComment(pr.Org, pr.Repo, pr.Number, fmt.Sprintf(
+			commentFreezeExceptionApproved, pr.BaseRef, commenter,
+		)); err != nil {
+			return err
+		}
+
+		return bot.switchLabel(pr, freezeExceptionRequestedLabel, freezeExceptionApprovedLabel, log)
+	}
+
+	if err := bot.cli.RemovePRLabel(pr.Org, pr.Repo, pr.Number, freezeExceptionRequestedLabel); err != nil {
+		return err
+	}
+
+	return bot.cli.CreatePRComment(pr.Org, pr.Repo, pr.Number, fmt.Sprintf(
+		commentFreezeExceptionRejected, commenter,
+	))
+}
+
+// isFreezeExceptionApproved checks whether the label of approved freeze exception is trusted.
+func (m *mergeHelper) isFreezeExceptionApproved(labels sets.String) (bool, error) {
+	return m.isLabelTrusted(
+		freezeExceptionApprovedLabel, labels, freezeExceptionDecision(m.pr.GetBase().GetRef()),
+	)
+}
+
+// freezeExceptionDecision decides on the freeze exception by the approval into the branch.
+// The rejection, the revocation and a new request of it cancel the approval.
+func freezeExceptionDecision(branch string) commentDecision {
+	return func(comment string) (bool, bool) {
+		if v, ok := parseComment(comment, commentFreezeExceptionApproved); ok {
+			return v[0] == branch, true
+		}
+
+		if isCommentOf(comment, commentFreezeExceptionRejected) ||
+			isCommentOf(comment, commentFreezeExceptionRevoked) ||
+			isCommentOf(comment, commentFreezeExceptionAsked) {
+			return false, true
+		}
+
+		return false, false
+	}
+}
+
+// handleFreezeExceptionReset revokes the approved freeze exception when the branches of pr
+// are changed, because the new commits or the new target branch are not reviewed by the
+// branch owners.
+func (bot *robot) handleFreezeExceptionReset(e *sdk.PullRequestEvent, log *logrus.Entry) error {
+	if a := giteeclient.GetPullRequestAction(e); a != giteeclient.PRActionChangedSourceBranch &&
+		a != giteeclient.PRActionChangedTargetBranch {
+		return nil
+	}
+
+	pr := giteeclient.GetPRInfoByPREvent(e)
+	if !pr.Labels.Has(freezeExceptionApprovedLabel) {
+		return nil
+	}
+
+	if err := bot.cli.RemovePRLabel(pr.Org, pr.Repo, pr.Number, freezeExceptionApprovedLabel); err != nil {
+		return err
+	}
+
+	return bot.cli.CreatePRComment(pr.Org, pr.Repo, pr.Number, fmt.Sprintf(
+		commentFreezeExceptionRevoked, pr.Author,
+	))
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFreezeExceptionDecision(t *testing.T) {
+	cases := []struct {
+		name     string
+		comment  string
+		approved bool
+		ok       bool
+	}{
+		{"approved", fmt.Sprintf(commentFreezeExceptionApproved, "master", "owner"), true, true},
+		{"approved into other branch", fmt.Sprintf(commentFreezeExceptionApproved, "release", "owner"), false, true},
+		{"rejected", fmt.Sprintf(commentFreezeExceptionRejected, "owner"), false, true},
+		{"revoked", fmt.Sprintf(commentFreezeExceptionRevoked, "author"), false, true},
+		{"asked again", fmt.Sprintf(commentFreezeExceptionAsked, "author", "fix CVE", "owner"), false, true},
+		{"not a decision", fmt.Sprintf(commentNotFrozen, "author"), false, false},
+	}
+
+	decide := freezeExceptionDecision("master")
+
+	for _, c := range cases {
+		if approved, ok := decide(c.comment); approved != c.approved || ok != c.ok {
+			t.Errorf("%s: got %t, %t, want %t, %t", c.name, approved, ok, c.approved, c.ok)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/opensourceways/community-robot-lib/giteeclient"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	commentFreezeStatus          = "@%s , the freeze status of the target branch ***%s*** is as below.\n\n%s"
+	commentFailedToGetFreezeInfo = "@%s , failed to get the freeze status of the target branch ***%s***, please try again later."
+
+	freezeScheduleNone      = "None"
+	freezeSchedulePermanent = "Frozen permanently"
+)
+
+var regFreezeStatus = regexp.MustCompile(`(?mi)^/freeze-status\s*$`)
+
+// handleFreezeStatus shows the freeze status of the target branch of pr.
+func (bot *robot) handleFreezeStatus(e *sdk.NoteEvent, cfg *botConfig, log *logrus.Entry) error {
+	ne := giteeclient.NewPRNoteEvent(e)
+
+	if !ne.IsPullRequest() ||
+		!ne.IsCreatingCommentEvent() ||
+		!regFreezeStatus.MatchString(ne.GetComment()) {
+		return nil
+	}
+
+	org, repo := ne.GetOrgRep()
+	h := mergeHelper{
+		cfg:      cfg,
+		org:      org,
+		repo:     repo,
+		cli:      bot.cli,
+		cacheCli: bot.cacheCli,
+		botLogin: bot.botLogin,
+		freeze:   bot.freezeCache,
+		teams:    bot.teamCache,
+		pr:       ne.GetPullRequest(),
+	}
+
+	pr := ne.GetPRInfo()
+	commenter := ne.GetCommenter()
+
+	// the error is logged rather than commented, because it may contain the url of api with the token.
+	rule, err := h.getFreezeInfo()
+	if err != nil {
+		log.WithError(err).Error("get the freeze status")
+
+		return bot.cli.CreatePRComment(org, repo, pr.Number, fmt.Sprintf(
+			commentFailedToGetFreezeInfo, commenter, pr.BaseRef,
+		))
+	}
+
+	approved, err := h.isFreezeExceptionApproved(pr.Labels)
+	if err != nil {
+		log.WithError(err).Error("check the freeze exception")
+	}
+
+	return bot.cli.CreatePRComment(org, repo, pr.Number, fmt.Sprintf(
+		commentFreezeStatus, commenter, pr.BaseRef, genFreezeStatusTable(rule, pr, approved),
+	))
+}
+
+func genFreezeStatusTable(rule *freezeRule, pr giteeclient.PRInfo, exceptionApproved bool) string {
+	s := []string{"| Item | Value |", "| --- | --- |"}
+
+	row := func(k, v string) {
+		s = append(s, fmt.Sprintf("| %s | %s |", k, escapeTableCell(v)))
+	}
+
+	if rule == nil {
+		row("Frozen", "No")
+		row("Freeze rule", "No freeze rule matches the target branch.")
+
+		return strings.Join(s, "\n")
+	}
+
+	frozen := "No"
+	if rule.isFrozen() {
+		frozen = "Yes"
+	}
+
+	exception := "None"
+	if exceptionApproved {
+		exception = "Approved"
+	} else if pr.Labels.Has(freezeExceptionRequestedLabel) {
+		exception = "Requested"
+	}
+
+	row("Frozen", frozen)
+	row("Freeze file", rule.file.String())
+	row("Freeze rule", rule.describe())
+	row("Schedule", rule.schedule())
+	row("Owners", strings.Join(rule.Owner, ", "))
+	row("Freeze exception", exception)
+
+	return strings.Join(s, "\n")
+}
+
+// schedule describes when the branch is frozen.
+func (fi freezeItem) schedule() string {
+	var s []string
+
+	if fi.Frozen {
+		s = append(s, freezeSchedulePermanent)
+	}
+
+	if fi.hasSchedule() {
+		start, end := "-", "-"
+		if !fi.start.IsZero() {
+			start = fi.start.Format(mergeWindowTimeFormat)
+		}
+
+		if !fi.end.IsZero() {
+			end = fi.end.Format(mergeWindowTimeFormat)
+		}
+
+		s = append(s, fmt.Sprintf("From %s to %s", start, end))
+	}
+
+	if len(s) == 0 {
+		return freezeScheduleNone
+	}
+
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// gitClient runs git commands on the local working copies of repositories.
+type gitClient struct {
+	userName  string
+	userEmail string
+	getToken  func() []byte
+
+	// remoteURL returns the url of repository. It can be replaced by
+	// the path of a local bare repository.
+	remoteURL func(org, repo string) string
+}
+
+func newGitClient(userName, userEmail string, getToken func() []byte) *gitClient {
+	g := &gitClient{
+		userName:  userName,
+		userEmail: userEmail,
+		getToken:  getToken,
+	}
+
+	g.remoteURL = func(org, repo string) string {
+		return fmt.Sprintf(
+			"https://%s:%s@gitee.com/%s/%s.git",
+			g.userName, string(g.getToken()), org, repo,
+		)
+	}
+
+	return g
+}
+
+// workingCopy is a local clone of one branch of repository.
+type workingCopy struct {
+	dir string
+	git *gitClient
+}
+
+func (g *gitClient) clone(org, repo, branch string) (*workingCopy, error) {
+	dir, err := ioutil.TempDir("", "review-")
+	if err != nil {
+		return nil, err
+	}
+
+	w := &workingCopy{dir: dir, git: g}
+
+	if _, err := w.run(
+		"clone", "--quiet", "--single-branch", "--branch", branch,
+		g.remoteURL(org, repo), ".",
+	); err != nil {
+		w.clean()
+
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *workingCopy) clean() {
+	_ = os.RemoveAll(w.dir)
+}
+
+func (w *workingCopy) fetch(ref string) error {
+	_, err := w.run("fetch", "--quiet", "origin", ref)
+
+	return err
+}
+
+func (w *workingCopy) checkoutNewBranch(branch string) error {
+	_, err := w.run("checkout", "--quiet", "-b", branch)
+
+	return err
+}
+
+// cherryPick applies the commits in order. It aborts and returns false when there are conflicts.
+func (w *workingCopy) cherryPick(commits []string) (bool, error) {
+	args := append([]string{"cherry-pick", "-x"}, commits...)
+
+	if _, err := w.run(args...); err != nil {
+		if _, err1 := w.run("cherry-pick", "--abort"); err1 != nil {
+			return false, err
+		}
+
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (w *workingCopy) push(branch string) error {
+	_, err := w.run("push", "--quiet", "origin", branch)
+
+	return err
+}
+
+func (w *workingCopy) run(args ...string) (string, error) {
+	g := w.git
+	args = append(
+		[]string{"-c", "user.name=" + g.userName, "-c", "user.email=" + g.userEmail},
+		args...,
+	)
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = w.dir
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err := cmd.Run()
+	output := g.hideToken(out.String())
+
+	if err != nil {
+		return output, fmt.Errorf(
+			"git %s failed, err:%s, output:%s",
+			g.hideToken(strings.Join(args, " ")), err.Error(), output,
+		)
+	}
+
+	return output, nil
+}
+
+func (g *gitClient) hideToken(s string) string {
+	if t := string(g.getToken()); t != "" {
+		return strings.ReplaceAll(s, t, "***")
+	}
+
+	return s
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testRemote is a local bare repository with a master branch and a pull request.
+type testRemote struct {
+	t    *testing.T
+	root string
+	git  *gitClient
+	// local is the working copy to prepare the commits of remote.
+	local *workingCopy
+}
+
+func newTestRemote(t *testing.T) *testRemote {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root, err := ioutil.TempDir("", "review-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := newGitClient("robot", "robot@example.com", func() []byte { return nil })
+	g.remoteURL = func(org, repo string) string {
+		return filepath.Join(root, org, repo+".git")
+	}
+
+	r := &testRemote{t: t, root: root, git: g}
+
+	bare := &workingCopy{dir: root, git: g}
+	r.run(bare, "init", "--quiet", "--bare", g.remoteURL("org", "repo"))
+
+	local := filepath.Join(root, "local")
+	if err := os.Mkdir(local, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	r.local = &workingCopy{dir: local, git: g}
+	r.run(r.local, "init", "--quiet")
+	r.run(r.local, "checkout", "--quiet", "-b", "master")
+	r.run(r.local, "remote", "add", "origin", g.remoteURL("org", "repo"))
+	r.commit("a.txt", "a\n")
+	r.run(r.local, "push", "--quiet", "origin", "master")
+
+	return r
+}
+
+func (r *testRemote) clean() {
+	_ = os.RemoveAll(r.root)
+}
+
+func (r *testRemote) run(w *workingCopy, args ...string) string {
+	out, err := w.run(args...)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+
+	return strings.TrimSpace(out)
+}
+
+// commit writes the file and commits it on the current branch of local, then returns the sha.
+func (r *testRemote) commit(file, content string) string {
+	if err := ioutil.WriteFile(filepath.Join(r.local.dir, file), []byte(content), 0644); err != nil {
+		r.t.Fatal(err)
+	}
+
+	r.run(r.local, "add", file)
+	r.run(r.local, "commit", "--quiet", "-m", "update "+file)
+
+	return r.run(r.local, "rev-parse", "HEAD")
+}
+
+// createPR commits the file on a branch from master and pushes it as the head of pull request 1.
+func (r *testRemote) createPR(file, content string) string {
+	r.run(r.local, "checkout", "--quiet", "-b", "feature", "master")
+	sha := r.commit(file, content)
+	r.run(r.local, "push", "--quiet", "origin", "feature:refs/pull/1/head")
+	r.run(r.local, "checkout", "--quiet", "master")
+
+	return sha
+}
+
+func TestGitCherryPick(t *testing.T) {
+	r := newTestRemote(t)
+	defer r.clean()
+
+	sha := r.createPR("b.txt", "b\n")
+
+	w, err := r.git.clone("org", "repo", "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.clean()
+
+	if err := w.fetch("refs/pull/1/head"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.checkoutNewBranch("cherry-pick-1-to-master"); err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := w.cherryPick([]string{sha})
+	if err != nil || !ok {
+		t.Fatalf("cherry-pick: ok=%t, err=%v", ok, err)
+	}
+
+	if err := w.push("cherry-pick-1-to-master"); err != nil {
+		t.Fatal(err)
+	}
+
+	bare := &workingCopy{dir: r.git.remoteURL("org", "repo"), git: r.git}
+	msg := r.run(bare, "log", "-1", "--format=%B", "cherry-pick-1-to-master")
+	if !strings.Contains(msg, "(cherry picked from commit "+sha+")") {
+		t.Errorf("unexpected message of the cherry-picked commit: %s", msg)
+	}
+
+	if v := r.run(bare, "show", "cherry-pick-1-to-master:b.txt"); v != "b" {
+		t.Errorf("unexpected content of b.txt: %s", v)
+	}
+}
+
+func TestGitCherryPickConflicts(t *testing.T) {
+	r := newTestRemote(t)
+	defer r.clean()
+
+	sha := r.createPR("a.txt", "pr\n")
+
+	r.commit("a.txt", "master\n")
+	r.run(r.local, "push", "--quiet", "origin", "master")
+
+	w, err := r.git.clone("org", "repo", "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.clean()
+
+	if err := w.fetch("refs/pull/1/head"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.checkoutNewBranch("cherry-pick-1-to-master"); err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := w.cherryPick([]string{sha})
+	if err != nil || ok {
+		t.Fatalf("cherry-pick: expected conflicts, got ok=%t, err=%v", ok, err)
+	}
+
+	// the cherry-pick is aborted, so the working copy is clean.
+	if v := r.run(w, "status", "--porcelain"); v != "" {
+		t.Errorf("the working copy is not clean after abort: %s", v)
+	}
+
+	if _, err := os.Stat(filepath.Join(w.dir, ".git", "CHERRY_PICK_HEAD")); !os.IsNotExist(err) {
+		t.Errorf("the cherry-pick is not aborted")
+	}
+}
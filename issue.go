@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	issueStateClosed   = "closed"
+	issueStateRejected = "rejected"
+
+	checkNameLinkedIssue = "Linked issue"
+
+	msgNoLinkedIssue      = "PR does not refer to any issue with a closing keyword of Fixes, Closes or Resolves, please add it to the title or body of PR, such as 'Fixes #I4ABCD'."
+	msgLinkedIssueNotOpen = "PR does not fix any open issue: %s"
+	msgLinkedIssueOpen    = "PR fixes the open issues which will be closed after it is merged: %s"
+	msgIssueClosedByPR    = "This issue is fixed by the pull request %s which has been merged, so it is closed."
+	msgIssueNotFound      = "not found"
+)
+
+// issueReferPattern is the pattern of issue reference, whose group is the number of issue.
+const issueReferPattern = `#(I[0-9A-Z]+)\b`
+
+// regClosingIssueRefer matches the reference of issue which is fixed by PR, such as 'Fixes #I4ABCD'.
+var regClosingIssueRefer = regexp.MustCompile(`\b(?i:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+` + issueReferPattern)
+
+// parseClosingIssues parses the references of issue with a closing keyword, such as 'Fixes #I4ABCD'.
+// Only these issues are linked to the pr, because the others referred may be related but not fixed
+// by it, and the linked issues are closed after the pr is merged.
+func parseClosingIssues(pr *sdk.PullRequestHook) []string {
+	v := sets.NewString()
+
+	for _, m := range regClosingIssueRefer.FindAllStringSubmatch(pr.Title+"\n"+pr.Body, -1) {
+		v.Insert(m[1])
+	}
+
+	return v.List()
+}
+
+func isIssueOpen(issue *sdk.Issue) bool {
+	return issue.State != issueStateClosed && issue.State != issueStateRejected
+}
+
+// getOpenLinkedIssues returns the open issues the pr refers to and the states of the others.
+// The issues are found in the whole org, because they may belong to the other repos.
+func (m *mergeHelper) getOpenLinkedIssues(numbers []string) ([]sdk.Issue, []string) {
+	var open []sdk.Issue
+	var others []string
+
+	for _, n := range numbers {
+		issue, err := m.cli.GetOrgIssue(m.org, n)
+		if err != nil {
+			others = append(others, fmt.Sprintf("#%s(%s)", n, msgIssueNotFound))
+
+			continue
+		}
+
+		if isIssueOpen(&issue) {
+			open = append(open, issue)
+		} else {
+			others = append(others, fmt.Sprintf("#%s(%s)", n, issue.State))
+		}
+	}
+
+	return open, others
+}
+
+func (m *mergeHelper) checkLinkedIssue() mergeCheck {
+	r := mergeCheck{name: checkNameLinkedIssue}
+
+	numbers := parseClosingIssues(m.pr)
+	if len(numbers) == 0 {
+		r.detail = msgNoLinkedIssue
+
+		return r
+	}
+
+	open, others := m.getOpenLinkedIssues(numbers)
+	if len(open) == 0 {
+		r.detail = fmt.Sprintf(msgLinkedIssueNotOpen, strings.Join(others, ", "))
+
+		return r
+	}
+
+	s := make([]string, len(open))
+	for i := range open {
+		s[i] = "#" + open[i].Number
+	}
+
+	r.passed = true
+	r.detail = fmt.Sprintf(msgLinkedIssueOpen, strings.Join(s, ", "))
+
+	return r
+}
+
+// closeLinkedIssues comments on and closes the open issues which the merged pr refers to
+// with a closing keyword, which are the ones accepted by checkLinkedIssue.
+func (bot *robot) closeLinkedIssues(h *mergeHelper, log *logrus.Entry) {
+	numbers := parseClosingIssues(h.pr)
+	if len(numbers) == 0 {
+		return
+	}
+
+	open, _ := h.getOpenLinkedIssues(numbers)
+
+	for i := range open {
+		issue := &open[i]
+
+		repo := h.repo
+		if issue.Repository != nil && issue.Repository.Path != "" {
+			repo = issue.Repository.Path
+		}
+
+		err := bot.cli.CreateIssueComment(
+			h.org, repo, issue.Number,
+			fmt.Sprintf(msgIssueClosedByPR, h.pr.HtmlUrl),
+		)
+		if err != nil {
+			log.WithError(err).Errorf("comment on issue: %s", issue.Number)
+		}
+
+		_, err = bot.cli.UpdateIssue(h.org, issue.Number, sdk.IssueUpdateParam{
+			Repo:  repo,
+			State: issueStateClosed,
+		})
+		if err != nil {
+			log.WithError(err).Errorf("close issue: %s", issue.Number)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// fakeIssueClient gets the open issues of org and records the closed ones.
+// The other methods of iClient are not implemented.
+type fakeIssueClient struct {
+	iClient
+
+	issues sets.String
+	closed []string
+}
+
+func (c *fakeIssueClient) GetOrgIssue(org, number string) (sdk.Issue, error) {
+	if !c.issues.Has(number) {
+		return sdk.Issue{}, errors.New("404 not found")
+	}
+
+	return sdk.Issue{Number: number, State: "open"}, nil
+}
+
+func (c *fakeIssueClient) CreateIssueComment(org, repo string, number string, comment string) error {
+	return nil
+}
+
+func (c *fakeIssueClient) UpdateIssue(owner, number string, param sdk.IssueUpdateParam) (sdk.Issue, error) {
+	if param.State == issueStateClosed {
+		c.closed = append(c.closed, number)
+	}
+
+	return sdk.Issue{}, nil
+}
+
+func TestParseClosingIssues(t *testing.T) {
+	cases := []struct {
+		title   string
+		body    string
+		closing []string
+	}{
+		{"fix the crash", "", nil},
+		{"fix the crash #I4ABCD", "", nil},
+		{"fix the crash", "Fixes #I4ABCD", []string{"I4ABCD"}},
+		{"fix the crash", "closes: #I4ABCD\nresolved #I5XYZ1", []string{"I4ABCD", "I5XYZ1"}},
+		{"fix the crash", "related to #I4ABCD, Fix #I5XYZ1", []string{"I5XYZ1"}},
+		{"fix the crash", "prefixes #I4ABCD", nil},
+		{"fix the crash", "Fixes #123", nil},
+	}
+
+	for _, c := range cases {
+		pr := &sdk.PullRequestHook{Title: c.title, Body: c.body}
+
+		if v := parseClosingIssues(pr); !reflect.DeepEqual(v, c.closing) && (len(v) > 0 || len(c.closing) > 0) {
+			t.Errorf("closing issues of %q: got %v, want %v", c.body, v, c.closing)
+		}
+	}
+}
+
+// TestLinkedIssuesClosed checks that the issues accepted by the check are exactly the ones closed after merge.
+func TestLinkedIssuesClosed(t *testing.T) {
+	cases := []struct {
+		name   string
+		body   string
+		passed bool
+		closed []string
+	}{
+		{"referred without closing keyword", "related to #I4ABCD", false, nil},
+		{"referred with and without closing keyword", "related to #I4ABCD, Fixes #I5XYZ1", true, []string{"I5XYZ1"}},
+		{"closing issue not found", "Fixes #I4ABCD, Fixes #I6NONE", true, []string{"I4ABCD"}},
+		{"no open issue", "Fixes #I6NONE", false, nil},
+	}
+
+	for _, c := range cases {
+		cli := &fakeIssueClient{issues: sets.NewString("I4ABCD", "I5XYZ1")}
+		h := mergeHelper{
+			pr:   &sdk.PullRequestHook{Number: 1, Body: c.body},
+			org:  "org",
+			repo: "repo",
+			cli:  cli,
+		}
+
+		if r := h.checkLinkedIssue(); r.passed != c.passed {
+			t.Errorf("%s: got passed=%t, want %t, detail: %s", c.name, r.passed, c.passed, r.detail)
+		}
+
+		(&robot{cli: cli}).closeLinkedIssues(&h, logrus.NewEntry(logrus.New()))
+
+		if !reflect.DeepEqual(cli.closed, c.closed) {
+			t.Errorf("%s: got closed %v, want %v", c.name, cli.closed, c.closed)
+		}
+	}
+}
@@ -0,0 +1,430 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// labelExpr is a boolean expression on the labels of PR. It supports the
+// operators of '!', '&&', '||' and parentheses. The operand is a label name
+// or a glob pattern of label, such as 'do-not-merge/*'.
+type labelExpr interface {
+	eval(labels sets.String) bool
+
+	// failed returns the operands which make the expression false.
+	failed(labels sets.String) labelExpr
+
+	// satisfied returns the operands which make the expression true.
+	satisfied(labels sets.String) labelExpr
+
+	String() string
+}
+
+type labelOperand struct {
+	pattern string
+}
+
+func (e labelOperand) eval(labels sets.String) bool {
+	if labels.Has(e.pattern) {
+		return true
+	}
+
+	for l := range labels {
+		if e.match(l) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e labelOperand) match(label string) bool {
+	ok, _ := path.Match(e.pattern, label)
+
+	return ok
+}
+
+func (e labelOperand) failed(labels sets.String) labelExpr {
+	return e
+}
+
+// satisfied returns the labels matched by the pattern.
+func (e labelOperand) satisfied(labels sets.String) labelExpr {
+	if labels.Has(e.pattern) {
+		return e
+	}
+
+	var xs []labelExpr
+	for _, l := range labels.List() {
+		if e.match(l) {
+			xs = append(xs, labelOperand{pattern: l})
+		}
+	}
+
+	if len(xs) == 1 {
+		return xs[0]
+	}
+
+	return orExpr{xs: xs}
+}
+
+func (e labelOperand) String() string {
+	return e.pattern
+}
+
+type notExpr struct {
+	x labelExpr
+}
+
+func (e notExpr) eval(labels sets.String) bool {
+	return !e.x.eval(labels)
+}
+
+func (e notExpr) failed(labels sets.String) labelExpr {
+	return notExpr{x: e.x.satisfied(labels)}
+}
+
+func (e notExpr) satisfied(labels sets.String) labelExpr {
+	return notExpr{x: e.x.failed(labels)}
+}
+
+func (e notExpr) String() string {
+	if _, ok := e.x.(labelOperand); ok {
+		return "!" + e.x.String()
+	}
+
+	return fmt.Sprintf("!(%s)", e.x.String())
+}
+
+type andExpr struct {
+	xs []labelExpr
+}
+
+func (e andExpr) eval(labels sets.String) bool {
+	for _, x := range e.xs {
+		if !x.eval(labels) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (e andExpr) failed(labels sets.String) labelExpr {
+	for _, x := range e.xs {
+		if !x.eval(labels) {
+			return x.failed(labels)
+		}
+	}
+
+	return nil
+}
+
+func (e andExpr) satisfied(labels sets.String) labelExpr {
+	xs := make([]labelExpr, len(e.xs))
+	for i, x := range e.xs {
+		xs[i] = x.satisfied(labels)
+	}
+
+	return andExpr{xs: xs}
+}
+
+func (e andExpr) String() string {
+	return joinLabelExprs(e.xs, " && ")
+}
+
+type orExpr struct {
+	xs []labelExpr
+}
+
+func (e orExpr) eval(labels sets.String) bool {
+	for _, x := range e.xs {
+		if x.eval(labels) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// failed returns the failed operands of every alternative, because all of them are false.
+func (e orExpr) failed(labels sets.String) labelExpr {
+	xs := make([]labelExpr, len(e.xs))
+	for i, x := range e.xs {
+		xs[i] = x.failed(labels)
+	}
+
+	return orExpr{xs: xs}
+}
+
+func (e orExpr) satisfied(labels sets.String) labelExpr {
+	for _, x := range e.xs {
+		if x.eval(labels) {
+			return x.satisfied(labels)
+		}
+	}
+
+	return nil
+}
+
+func (e orExpr) String() string {
+	return joinLabelExprs(e.xs, " || ")
+}
+
+func joinLabelExprs(xs []labelExpr, sep string) string {
+	s := make([]string, len(xs))
+	for i, x := range xs {
+		if _, ok := x.(orExpr); ok {
+			s[i] = fmt.Sprintf("(%s)", x.String())
+		} else {
+			s[i] = x.String()
+		}
+	}
+
+	return strings.Join(s, sep)
+}
+
+// parseLabelExpr parses the expression by the grammar below.
+//
+//	expr    = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" unary | "(" expr ")" | operand
+func parseLabelExpr(s string) (labelExpr, error) {
+	p := labelExprParser{tokens: tokenizeLabelExpr(s)}
+
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid label expression: %s, err:%s", s, err.Error())
+	}
+
+	if t := p.peek(); t != "" {
+		return nil, fmt.Errorf("invalid label expression: %s, unexpected token: %s", s, t)
+	}
+
+	return e, nil
+}
+
+func tokenizeLabelExpr(s string) []string {
+	var tokens []string
+
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t':
+			i++
+
+		case c == '(' || c == ')' || c == '!':
+			tokens = append(tokens, string(c))
+			i++
+
+		case strings.HasPrefix(s[i:], "&&") || strings.HasPrefix(s[i:], "||"):
+			tokens = append(tokens, s[i:i+2])
+			i += 2
+
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t()!&|", rune(s[j])) {
+				j++
+			}
+
+			if j == i {
+				// a single '&' or '|'
+				j++
+			}
+
+			tokens = append(tokens, s[i:j])
+			i = j
+		}
+	}
+
+	return tokens
+}
+
+type labelExprParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *labelExprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+
+	return ""
+}
+
+func (p *labelExprParser) next() string {
+	t := p.peek()
+	p.pos++
+
+	return t
+}
+
+func (p *labelExprParser) parseOr() (labelExpr, error) {
+	x, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	xs := []labelExpr{x}
+	for p.peek() == "||" {
+		p.next()
+
+		if x, err = p.parseAnd(); err != nil {
+			return nil, err
+		}
+
+		xs = append(xs, x)
+	}
+
+	if len(xs) == 1 {
+		return xs[0], nil
+	}
+
+	return orExpr{xs: xs}, nil
+}
+
+func (p *labelExprParser) parseAnd() (labelExpr, error) {
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	xs := []labelExpr{x}
+	for p.peek() == "&&" {
+		p.next()
+
+		if x, err = p.parseUnary(); err != nil {
+			return nil, err
+		}
+
+		xs = append(xs, x)
+	}
+
+	if len(xs) == 1 {
+		return xs[0], nil
+	}
+
+	return andExpr{xs: xs}, nil
+}
+
+func (p *labelExprParser) parseUnary() (labelExpr, error) {
+	switch t := p.next(); t {
+	case "":
+		return nil, fmt.Errorf("unexpected end")
+
+	case "!":
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return notExpr{x: x}, nil
+
+	case "(":
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+
+		return x, nil
+
+	case ")", "&&", "||", "&", "|":
+		return nil, fmt.Errorf("unexpected token: %s", t)
+
+	default:
+		if err := validatePattern(t); err != nil {
+			return nil, fmt.Errorf("invalid label pattern: %s", t)
+		}
+
+		return labelOperand{pattern: t}, nil
+	}
+}
+
+// validatePattern checks the syntax of the whole glob pattern. path.Match can't be
+// used for it, because it returns at the first mismatch without scanning the rest.
+func validatePattern(p string) error {
+	for i := 0; i < len(p); i++ {
+		switch p[i] {
+		case '\\':
+			if i++; i >= len(p) {
+				return path.ErrBadPattern
+			}
+
+		case '[':
+			j, err := scanPatternClass(p, i+1)
+			if err != nil {
+				return err
+			}
+
+			i = j
+		}
+	}
+
+	return nil
+}
+
+// scanPatternClass scans the character class which starts at i, just after '[',
+// and returns the position of the closing ']'.
+func scanPatternClass(p string, i int) (int, error) {
+	if i < len(p) && p[i] == '^' {
+		i++
+	}
+
+	for n := 0; ; n++ {
+		if i < len(p) && p[i] == ']' && n > 0 {
+			return i, nil
+		}
+
+		lo, j, err := scanPatternClassChar(p, i)
+		if err != nil {
+			return 0, err
+		}
+
+		i = j
+		if p[i] == '-' {
+			hi, j, err := scanPatternClassChar(p, i+1)
+			if err != nil {
+				return 0, err
+			}
+
+			if hi < lo {
+				return 0, path.ErrBadPattern
+			}
+
+			i = j
+		}
+	}
+}
+
+// scanPatternClassChar scans a possibly escaped character of the character class
+// and returns it with the position after it, where the class must go on.
+func scanPatternClassChar(p string, i int) (rune, int, error) {
+	if i >= len(p) || p[i] == '-' || p[i] == ']' {
+		return 0, 0, path.ErrBadPattern
+	}
+
+	if p[i] == '\\' {
+		if i++; i >= len(p) {
+			return 0, 0, path.ErrBadPattern
+		}
+	}
+
+	r, n := utf8.DecodeRuneInString(p[i:])
+	if r == utf8.RuneError && n == 1 {
+		return 0, 0, path.ErrBadPattern
+	}
+
+	if i += n; i >= len(p) {
+		return 0, 0, path.ErrBadPattern
+	}
+
+	return r, i, nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestTokenizeLabelExpr(t *testing.T) {
+	cases := []struct {
+		expr   string
+		tokens []string
+	}{
+		{"", nil},
+		{"lgtm", []string{"lgtm"}},
+		{"  a &&b", []string{"a", "&&", "b"}},
+		{"a||b", []string{"a", "||", "b"}},
+		{"!(a && do-not-merge/*)", []string{"!", "(", "a", "&&", "do-not-merge/*", ")"}},
+		{"a\t||\t!b", []string{"a", "||", "!", "b"}},
+		{"a & b", []string{"a", "&", "b"}},
+		{"a|b", []string{"a", "|", "b"}},
+	}
+
+	for _, c := range cases {
+		if v := tokenizeLabelExpr(c.expr); !reflect.DeepEqual(v, c.tokens) {
+			t.Errorf("tokenize %q: got %q, want %q", c.expr, v, c.tokens)
+		}
+	}
+}
+
+func TestParseLabelExpr(t *testing.T) {
+	cases := []struct {
+		expr string
+		// want is the canonical form of expr, empty if expr is invalid.
+		want string
+	}{
+		{"lgtm", "lgtm"},
+		{"a && b || c", "a && b || c"},
+		{"a && (b || c)", "a && (b || c)"},
+		{"((a))", "a"},
+		{"!a && !(b || c)", "!a && !(b || c)"},
+		{"!!a", "!(!a)"},
+		{"kind/*", "kind/*"},
+		{"ci-[a-z]*", "ci-[a-z]*"},
+		{"", ""},
+		{"a &&", ""},
+		{"&& a", ""},
+		{"(a || b", ""},
+		{"a || b)", ""},
+		{"a b", ""},
+		{"a & b", ""},
+		{"!", ""},
+		{"ci-[", ""},
+		{"ci-[z-a]", ""},
+		{"ci-\\", ""},
+		{"a && ci-[]x", ""},
+	}
+
+	for _, c := range cases {
+		e, err := parseLabelExpr(c.expr)
+
+		if c.want == "" {
+			if err == nil {
+				t.Errorf("parse %q: expected error, got %s", c.expr, e)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("parse %q: unexpected error: %v", c.expr, err)
+		} else if v := e.String(); v != c.want {
+			t.Errorf("parse %q: got %s, want %s", c.expr, v, c.want)
+		}
+	}
+}
+
+func TestLabelExprFailed(t *testing.T) {
+	cases := []struct {
+		expr   string
+		labels []string
+		// failed is the operands which make expr false, empty if expr is true.
+		failed string
+	}{
+		{"lgtm", []string{"lgtm"}, ""},
+		{"lgtm", nil, "lgtm"},
+		{"kind/*", []string{"kind/bug"}, ""},
+		{"!do-not-merge/*", []string{"do-not-merge/hold"}, "!do-not-merge/hold"},
+		{"!do-not-merge/*", []string{"do-not-merge/hold", "do-not-merge/wip"}, "!(do-not-merge/hold || do-not-merge/wip)"},
+		{"a && b && c", []string{"a", "c"}, "b"},
+		{"a && (b || c)", []string{"a"}, "b || c"},
+		{"a && (b || c)", []string{"a", "c"}, ""},
+		{"(a && b) || c", []string{"a"}, "b || c"},
+		{"lgtm && (approved || sig-approved)", []string{"lgtm"}, "approved || sig-approved"},
+		{"lgtm && (approved || sig-approved && lgtm-2)", []string{"lgtm", "sig-approved"}, "approved || lgtm-2"},
+		{"(a && b) || (c && !d)", []string{"a", "c", "d"}, "b || !d"},
+		{"!(a || b)", []string{"b"}, "!b"},
+		{"!(a && b)", []string{"a", "b"}, "!(a && b)"},
+		{"!(a && b)", []string{"a"}, ""},
+	}
+
+	for _, c := range cases {
+		e, err := parseLabelExpr(c.expr)
+		if err != nil {
+			t.Fatalf("parse %q: %v", c.expr, err)
+		}
+
+		labels := sets.NewString(c.labels...)
+
+		if ok := e.eval(labels); ok != (c.failed == "") {
+			t.Errorf("eval %q on %v: got %t", c.expr, c.labels, ok)
+
+			continue
+		}
+
+		if c.failed == "" {
+			continue
+		}
+
+		if f := e.failed(labels); f == nil || f.String() != c.failed {
+			t.Errorf("failed %q on %v: got %v, want %s", c.expr, c.labels, f, c.failed)
+		}
+	}
+}
+
+func TestValidatePattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		valid   bool
+	}{
+		{"lgtm", true},
+		{"do-not-merge/*", true},
+		{"ci-?", true},
+		{"[a-c]x", true},
+		{"[^a-c]x", true},
+		{"a\\*", true},
+		{"x[\\]]", true},
+		{"", true},
+		{"a[", false},
+		{"a[]", false},
+		{"[c-a]", false},
+		{"[a-", false},
+		{"[-a]", false},
+		{"a\\", false},
+		// path.Match of go 1.15 accepts them when matching an empty string.
+		{"ab[", false},
+		{"x*[", false},
+	}
+
+	for _, c := range cases {
+		if err := validatePattern(c.pattern); (err == nil) != c.valid {
+			t.Errorf("validate %q: got %v, want valid=%t", c.pattern, err, c.valid)
+		}
+	}
+}
@@ -52,7 +52,7 @@ func (bot *robot) addLGTM(cfg *botConfig, e giteeclient.PRNoteEvent, log *logrus
 
 	commenter := e.GetCommenter()
 	if pr.Author == commenter {
-		return bot.cli.CreatePRComment(org, repo, number, commentAddLGTMBySelf)
+		return bot.notifyInStatusComment(e, cfg, commentAddLGTMBySelf)
 	}
 
 	v, err := bot.hasPermission(commenter, pr, cfg, log)
@@ -60,12 +60,12 @@ func (bot *robot) addLGTM(cfg *botConfig, e giteeclient.PRNoteEvent, log *logrus
 		return err
 	}
 	if !v {
-		return bot.cli.CreatePRComment(org, repo, number, fmt.Sprintf(
+		return bot.notifyInStatusComment(e, cfg, fmt.Sprintf(
 			commentNoPermissionForLabel, commenter, "add", lgtmLabel,
 		))
 	}
 
-	label := genLGTMLabel(commenter, cfg.LgtmCountsRequired)
+	label := genLGTMLabel(commenter, cfg.maxLgtmCountsRequired())
 	if label != lgtmLabel {
 		if err := bot.createLabelIfNeed(org, repo, label); err != nil {
 			log.WithError(err).Errorf("create repo label: %s", label)
@@ -89,14 +89,14 @@ func (bot *robot) removeLGTM(cfg *botConfig, e giteeclient.PRNoteEvent, log *log
 			return err
 		}
 		if !v {
-			return bot.cli.CreatePRComment(org, repo, number, fmt.Sprintf(
+			return bot.notifyInStatusComment(e, cfg, fmt.Sprintf(
 				commentNoPermissionForLabel, commenter, "remove", lgtmLabel,
 			))
 		}
 
 		return bot.cli.RemovePRLabel(
 			org, repo, number,
-			genLGTMLabel(commenter, cfg.LgtmCountsRequired),
+			genLGTMLabel(commenter, cfg.maxLgtmCountsRequired()),
 		)
 	}
 
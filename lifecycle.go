@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/opensourceways/community-robot-lib/giteeclient"
+	"github.com/sirupsen/logrus"
+)
+
+const prStateClosed = "closed"
+
+var (
+	regClosePR   = regexp.MustCompile(`(?mi)^/close\s*$`)
+	regReopenPR  = regexp.MustCompile(`(?mi)^/reopen\s*$`)
+	regRetitlePR = regexp.MustCompile(`(?mi)^/retitle\s+(.*\S)\s*$`)
+)
+
+// handleLifecycle handles the commands of /close, /reopen and /retitle which
+// can be used by the author of pr and the ones who have the permission.
+func (bot *robot) handleLifecycle(e *sdk.NoteEvent, cfg *botConfig, log *logrus.Entry) error {
+	ne := giteeclient.NewPRNoteEvent(e)
+
+	if !ne.IsPullRequest() || !ne.IsCreatingCommentEvent() {
+		return nil
+	}
+
+	var cmd string
+	var param sdk.PullRequestUpdateParam
+
+	comment := ne.GetComment()
+	state := ne.GetPullRequest().State
+
+	switch {
+	case state == prStateOpen && regClosePR.MatchString(comment):
+		cmd = "close"
+		param.State = prStateClosed
+
+	case state == prStateClosed && regReopenPR.MatchString(comment):
+		cmd = "reopen"
+		param.State = prStateOpen
+
+	case state == prStateOpen && regRetitlePR.MatchString(comment):
+		cmd = "retitle"
+		param.Title = strings.TrimSpace(regRetitlePR.FindStringSubmatch(comment)[1])
+
+	default:
+		return nil
+	}
+
+	pr := ne.GetPRInfo()
+
+	if commenter := ne.GetCommenter(); pr.Author != commenter {
+		v, err := bot.hasPermission(commenter, pr, cfg, log)
+		if err != nil {
+			return err
+		}
+
+		if !v {
+			return bot.notifyInStatusComment(ne, cfg, fmt.Sprintf(
+				commentNoPermissionForCmd, commenter, cmd,
+			))
+		}
+	}
+
+	_, err := bot.cli.UpdatePullRequest(pr.Org, pr.Repo, pr.Number, param)
+
+	return err
+}
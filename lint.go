@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/opensourceways/community-robot-lib/giteeclient"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	checkNameLint = "Lint"
+
+	msgLintPassed       = "The title of PR and the messages of commits pass the lint."
+	msgLintFailed       = "The lint is not passed: %s"
+	msgFailedToLint     = "Failed to get the commits of PR."
+	msgLintPassedNotice = "@%s , the lint of this pr is passed."
+	msgLintFailedNotice = "@%s , the lint of this pr is not passed, see the %s condition above."
+)
+
+var regLint = regexp.MustCompile(`(?mi)^/lint\s*$`)
+
+// lintConfig specifies the rules of linting the title of PR and the messages of commits.
+// Only the title of PR is linted when the merge method is squash.
+type lintConfig struct {
+	// TitlePattern is the regular expression which the title of PR and the
+	// subject of commit message must match, such as the one of conventional commit:
+	// '^(feat|fix|docs|style|refactor|test|chore)(\(.+\))?: .+'
+	TitlePattern string `json:"title_pattern,omitempty"`
+	regTitle     *regexp.Regexp
+
+	// MaxSubjectLength is the maximum length of the title of PR and the subject of commit message.
+	// It is not limited when it is 0.
+	MaxSubjectLength int `json:"max_subject_length,omitempty"`
+
+	// RequireIssueReference specifies whether the title or body of PR and the commit message
+	// must refer to an issue, such as '#I4ABCD'.
+	RequireIssueReference bool `json:"require_issue_reference,omitempty"`
+
+	// IssueReferencePattern is the regular expression of issue reference. The default is
+	// the same as the one to find the linked issues, which matches '#I4ABCD'.
+	IssueReferencePattern string `json:"issue_reference_pattern,omitempty"`
+	regIssueRefer         *regexp.Regexp
+
+	// ForbiddenWords are the words which must not be in the title of PR and the commit message.
+	ForbiddenWords []string `json:"forbidden_words,omitempty"`
+}
+
+func (c *lintConfig) validate() error {
+	if c.TitlePattern != "" {
+		v, err := regexp.Compile(c.TitlePattern)
+		if err != nil {
+			return fmt.Errorf("invalid title_pattern of lint: %s", err.Error())
+		}
+
+		c.regTitle = v
+	}
+
+	if c.MaxSubjectLength < 0 {
+		return fmt.Errorf("max_subject_length of lint must not be negative")
+	}
+
+	if c.IssueReferencePattern == "" {
+		c.IssueReferencePattern = issueReferPattern
+	}
+
+	v, err := regexp.Compile(c.IssueReferencePattern)
+	if err != nil {
+		return fmt.Errorf("invalid issue_reference_pattern of lint: %s", err.Error())
+	}
+	c.regIssueRefer = v
+
+	return nil
+}
+
+// lintMessage lints the subject and the whole content of title or commit message.
+func (c *lintConfig) lintMessage(kind, subject, content string) []string {
+	var r []string
+
+	if c.regTitle != nil && !c.regTitle.MatchString(subject) {
+		r = append(r, fmt.Sprintf("%s does not match the pattern of `%s`", kind, c.TitlePattern))
+	}
+
+	if n := len([]rune(subject)); c.MaxSubjectLength > 0 && n > c.MaxSubjectLength {
+		r = append(r, fmt.Sprintf(
+			"the subject of %s is %d characters which exceeds %d", kind, n, c.MaxSubjectLength,
+		))
+	}
+
+	if c.RequireIssueReference && !c.regIssueRefer.MatchString(content) {
+		r = append(r, fmt.Sprintf("%s does not refer to any issue", kind))
+	}
+
+	lower := strings.ToLower(content)
+	for _, w := range c.ForbiddenWords {
+		if strings.Contains(lower, strings.ToLower(w)) {
+			r = append(r, fmt.Sprintf("%s contains the forbidden word of `%s`", kind, w))
+		}
+	}
+
+	return r
+}
+
+func (m *mergeHelper) lint() ([]string, error) {
+	c := m.cfg.Lint
+	pr := m.pr
+
+	problems := c.lintMessage("the title of PR", pr.Title, pr.Title+"\n"+pr.Body)
+
+	if m.cfg.MergeMethod == mergeMethodSquash {
+		return problems, nil
+	}
+
+	commits, err := m.getCommits()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range commits {
+		item := &commits[i]
+		if item.Commit == nil {
+			continue
+		}
+
+		msg := item.Commit.Message
+		subject := strings.SplitN(msg, "\n", 2)[0]
+
+		problems = append(problems, c.lintMessage(
+			fmt.Sprintf("the message of commit %s", shortSHA(item.Sha)), subject, msg,
+		)...)
+	}
+
+	return problems, nil
+}
+
+func (m *mergeHelper) checkLint() mergeCheck {
+	r := mergeCheck{name: checkNameLint}
+
+	problems, err := m.lint()
+	if err != nil {
+		r.detail = msgFailedToLint
+		r.err = err
+
+		return r
+	}
+
+	if len(problems) > 0 {
+		r.detail = fmt.Sprintf(msgLintFailed, strings.Join(problems, "; "))
+	} else {
+		r.passed = true
+		r.detail = msgLintPassed
+	}
+
+	return r
+}
+
+// handleLint re-runs the lint and shows the result in the status comment.
+func (bot *robot) handleLint(e *sdk.NoteEvent, cfg *botConfig, log *logrus.Entry) error {
+	ne := giteeclient.NewPRNoteEvent(e)
+
+	if !ne.IsPullRequest() ||
+		!ne.IsPROpen() ||
+		!ne.IsCreatingCommentEvent() ||
+		cfg.Lint == nil ||
+		!regLint.MatchString(ne.GetComment()) {
+		return nil
+	}
+
+	org, repo := ne.GetOrgRep()
+	h := mergeHelper{
+		cfg:      cfg,
+		org:      org,
+		repo:     repo,
+		cli:      bot.cli,
+		cacheCli: bot.cacheCli,
+		botLogin: bot.botLogin,
+		freeze:   bot.freezeCache,
+		teams:    bot.teamCache,
+		pr:       ne.GetPullRequest(),
+		trigger:  ne.GetCommenter(),
+	}
+
+	// the lint is one of the merge conditions, so take its result from them.
+	checks, _ := h.canMerge()
+
+	notice := fmt.Sprintf(msgLintPassedNotice, ne.GetCommenter())
+	for i := range checks {
+		if item := &checks[i]; item.name == checkNameLint && !item.passed {
+			notice = fmt.Sprintf(msgLintFailedNotice, ne.GetCommenter(), checkNameLint)
+		}
+	}
+
+	return bot.updateStatusComment(&h, checks, notice)
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+
+	return sha
+}
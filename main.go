@@ -2,10 +2,13 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"net/url"
 	"os"
+	"os/exec"
+	"time"
+	_ "time/tzdata"
 
-	"github.com/opensourceways/community-robot-lib/giteeclient"
 	libplugin "github.com/opensourceways/community-robot-lib/giteeplugin"
 	"github.com/opensourceways/community-robot-lib/logrusutil"
 	liboptions "github.com/opensourceways/community-robot-lib/options"
@@ -19,6 +22,11 @@ type options struct {
 	gitee         liboptions.GiteeOptions
 	cacheEndpoint string
 	maxRetries    int
+	gitUserEmail  string
+	stateFile     string
+
+	freezeFileCacheTTL time.Duration
+	freezeSyncInterval time.Duration
 }
 
 func (o *options) Validate() error {
@@ -26,6 +34,14 @@ func (o *options) Validate() error {
 		return err
 	}
 
+	if o.freezeFileCacheTTL <= 0 {
+		return fmt.Errorf("freeze-file-cache-ttl must be positive")
+	}
+
+	if o.freezeSyncInterval <= 0 {
+		return fmt.Errorf("freeze-sync-interval must be positive")
+	}
+
 	if err := o.plugin.Validate(); err != nil {
 		return err
 	}
@@ -40,6 +56,10 @@ func gatherOptions(fs *flag.FlagSet, args ...string) options {
 	o.plugin.AddFlags(fs)
 	fs.StringVar(&o.cacheEndpoint, "cache-endpoint", "", "The endpoint of repo file cache")
 	fs.IntVar(&o.maxRetries, "max-retries", 3, "The number of failed retry attempts to call the cache api")
+	fs.DurationVar(&o.freezeFileCacheTTL, "freeze-file-cache-ttl", 5*time.Minute, "The time to cache the freeze files, they are refreshed once the branch storing them is pushed")
+	fs.StringVar(&o.stateFile, "state-file", "", "The file to persist the state of bot across restarts, such as the queued merges. It is kept in memory only if empty")
+	fs.DurationVar(&o.freezeSyncInterval, "freeze-sync-interval", time.Minute, "The interval to check whether the freeze starts or ends at the scheduled time")
+	fs.StringVar(&o.gitUserEmail, "git-user-email", "", "The email of bot used to commit by git. The default is <bot login>@users.noreply.gitee.com")
 
 	_ = fs.Parse(args)
 
@@ -54,15 +74,41 @@ func main() {
 		logrus.WithError(err).Fatal("Invalid options")
 	}
 
+	if _, err := exec.LookPath("git"); err != nil {
+		logrus.WithError(err).Warn("git is not found, /cherry-pick will fail.")
+	}
+
 	secretAgent := new(secret.Agent)
 	if err := secretAgent.Start([]string{o.gitee.TokenPath}); err != nil {
 		logrus.WithError(err).Fatal("Error starting secret agent.")
 	}
 
-	c := giteeclient.NewClient(secretAgent.GetTokenGenerator(o.gitee.TokenPath))
+	getToken := secretAgent.GetTokenGenerator(o.gitee.TokenPath)
+	c := newClient(getToken)
 	s := cache.NewSDK(o.cacheEndpoint, o.maxRetries)
 
-	p := newRobot(c, s)
+	bot, err := c.GetBot()
+	if err != nil {
+		logrus.WithError(err).Fatal("Error getting bot name.")
+	}
+
+	email := o.gitUserEmail
+	if email == "" {
+		email = bot.Login + "@users.noreply.gitee.com"
+	}
+
+	store, err := newStateStore(o.stateFile)
+	if err != nil {
+		logrus.WithError(err).Fatal("Error loading state.")
+	}
+
+	p := newRobot(c, s, newGitClient(bot.Login, email, getToken), bot.Login, o.freezeFileCacheTTL, store)
+
+	if err := p.queue.restore(); err != nil {
+		logrus.WithError(err).Error("Error restoring the merge queue.")
+	}
+
+	go p.watchFreeze(o.freezeSyncInterval)
 
 	libplugin.Run(p, o.plugin)
 
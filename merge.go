@@ -1,29 +1,66 @@
 package main
 
 import (
-	"encoding/base64"
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	sdk "gitee.com/openeuler/go-gitee/gitee"
 	"github.com/opensourceways/community-robot-lib/giteeclient"
+	cache "github.com/opensourceways/repo-file-cache/sdk"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/sets"
-	"sigs.k8s.io/yaml"
 )
 
 const (
-	msgPRConflicts        = "PR conflicts to the target branch."
-	msgMissingLabels      = "PR does not have these lables: %s"
-	msgInvalidLabels      = "PR should remove these labels: %s"
-	msgNotEnoughLGTMLabel = "PR needs %d lgtm labels and now gets %d"
-	msgFrozenWithOwner    = "The target branch of PR has been frozen and it can be merge only by branch owners: %s"
+	msgPRConflicts             = "PR conflicts to the target branch."
+	msgPRNoConflicts           = "PR has no conflicts to the target branch."
+	msgMissingLabels           = "PR does not have these lables: %s"
+	msgHasLabels               = "PR has these labels: %s"
+	msgInvalidLabels           = "PR should remove these labels: %s"
+	msgNoInvalidLabels         = "PR does not have any of these labels: %s"
+	msgNotEnoughLGTMLabel      = "PR needs %d lgtm labels and now gets %d"
+	msgFrozenWithOwner         = "The target branch of PR has been frozen and it can be merge only by branch owners: %s"
+	msgBranchNotFrozen         = "The target branch of PR is not frozen."
+	msgBranchWillFreeze        = "The target branch of PR is not frozen and will be frozen at %s."
+	msgFrozenUntil             = "The target branch of PR has been frozen until %s and it can be merge only by branch owners: %s"
+	msgFreezeRuleMatched       = "%s The freeze rule matched is %s."
+	msgFailedToGetFreezeOwners = "%s Failed to resolve some of the owners."
+	msgFailedToGetFreeze       = "Failed to get the freeze state of the target branch."
+
+	msgFailedToCheckFreezeException = "Failed to check the freeze exception."
+
+	msgLabelExprSatisfied    = "The label expression `%s` is satisfied."
+	msgLabelExprNotSatisfied = "The label expression `%s` is not satisfied, because `%s` is false."
+
+	msgMergeWindowOpen    = "The merge window is open."
+	msgMergeWindowClosed  = "The merge window is closed, PR will merge at %s if the other conditions are met."
+	msgMergeWindowNotOpen = "The merge window is closed and will not open in the next %d days."
+
+	msgFailedToGetStatuses   = "Failed to get the statuses of the head commit."
+	msgStatusesNotSuccessful = "These status checks are not successful: %s"
+	msgStatusesSuccessful    = "These status checks are successful: %s"
+
+	checkNameConflict        = "Conflict"
+	checkNameLGTM            = "LGTM"
+	checkNameApproved        = "Approved"
+	checkNameRequiredLabels  = "Required labels"
+	checkNameForbiddenLabels = "Forbidden labels"
+	checkNameLabelExpression = "Label expression"
+	checkNameStatusChecks    = "Status checks"
+	checkNameFreeze          = "Freeze"
+	checkNameMergeWindow     = "Merge window"
+
+	statusSuccess  = "success"
+	statusPending  = "pending"
+	prActionUpdate = "update"
+	prStateOpen    = "open"
 )
 
 var regCheckPr = regexp.MustCompile(`(?mi)^/check-pr\s*$`)
 
-func (bot *robot) handleCheckPR(e *sdk.NoteEvent, cfg *botConfig) error {
+func (bot *robot) handleCheckPR(e *sdk.NoteEvent, cfg *botConfig, log *logrus.Entry) error {
 	ne := giteeclient.NewPRNoteEvent(e)
 
 	if !ne.IsPullRequest() ||
@@ -33,58 +70,159 @@ func (bot *robot) handleCheckPR(e *sdk.NoteEvent, cfg *botConfig) error {
 		return nil
 	}
 
-	return bot.tryMerge(ne, cfg, true, nil)
+	return bot.tryMerge(ne, cfg, true, log)
 }
 
 func (bot *robot) tryMerge(e giteeclient.PRNoteEvent, cfg *botConfig, addComment bool, log *logrus.Entry) error {
 	org, repo := e.GetOrgRep()
 
 	h := mergeHelper{
-		cfg:     cfg,
-		org:     org,
-		repo:    repo,
-		cli:     bot.cli,
-		pr:      e.GetPullRequest(),
-		trigger: e.GetCommenter(),
+		cfg:      cfg,
+		org:      org,
+		repo:     repo,
+		cli:      bot.cli,
+		cacheCli: bot.cacheCli,
+		botLogin: bot.botLogin,
+		freeze:   bot.freezeCache,
+		teams:    bot.teamCache,
+		pr:       e.GetPullRequest(),
+		trigger:  e.GetCommenter(),
 	}
 
-	if r, ok := h.canMerge(); !ok {
-		if len(r) > 0 && addComment {
-			return bot.cli.CreatePRComment(
-				org, repo, e.GetPRNumber(),
-				fmt.Sprintf(
-					"@%s , this pr is not mergeable and the reasons are below:\n%s",
-					e.GetCommenter(), strings.Join(r, "\n"),
-				),
-			)
-		}
+	mention := ""
+	if addComment {
+		mention = e.GetCommenter()
 	}
 
-	return h.merge()
+	return bot.mergeOrReport(&h, mention, log)
 }
 
-func (bot *robot) handleLabelUpdate(e *sdk.PullRequestEvent, cfg *botConfig) error {
-	if giteeclient.GetPullRequestAction(e) != giteeclient.PRActionUpdatedLabel {
+// handlePRUpdate re-evaluates the merge conditions when the labels or the target branch
+// of pr are changed. Gitee does not deliver the event of commit status, so the pending
+// status checks are re-checked periodically, see queueStatusRecheck.
+func (bot *robot) handlePRUpdate(e *sdk.PullRequestEvent, cfg *botConfig, log *logrus.Entry) error {
+	if a := giteeclient.GetPullRequestAction(e); a != giteeclient.PRActionUpdatedLabel &&
+		a != giteeclient.PRActionChangedTargetBranch {
 		return nil
 	}
 
 	org, repo := giteeclient.GetOwnerAndRepoByPREvent(e)
 
 	h := mergeHelper{
-		cfg:  cfg,
-		org:  org,
-		repo: repo,
-		cli:  bot.cli,
-		pr:   e.GetPullRequest(),
+		cfg:      cfg,
+		org:      org,
+		repo:     repo,
+		cli:      bot.cli,
+		cacheCli: bot.cacheCli,
+		botLogin: bot.botLogin,
+		freeze:   bot.freezeCache,
+		teams:    bot.teamCache,
+		pr:       e.GetPullRequest(),
+	}
+
+	return bot.mergeOrReport(&h, "", log)
+}
+
+// mergeOrReport evaluates the merge conditions, shows the result in the status
+// comment and merges the pr if all of them are met. The mention will be notified
+// if it is not empty and the pr is not mergeable.
+func (bot *robot) mergeOrReport(h *mergeHelper, mention string, log *logrus.Entry) error {
+	checks, ok := h.canMerge()
+
+	notice := ""
+	if !ok && mention != "" {
+		notice = fmt.Sprintf(msgNotMergeable, mention)
+	}
+
+	if err := bot.updateStatusComment(h, checks, notice); err != nil {
+		log.WithError(err).Error("update status comment")
+	}
+
+	if ok {
+		if err := h.merge(); err != nil {
+			return err
+		}
+
+		if h.cfg.RequireLinkedIssue {
+			bot.closeLinkedIssues(h, log)
+		}
+
+		return nil
 	}
 
-	if _, ok := h.canMerge(); ok {
-		return h.merge()
+	if isWaitingForMergeWindow(checks) {
+		bot.queueMerge(h, log)
+	} else if isWaitingForStatusChecks(checks) {
+		bot.queueStatusRecheck(h, log)
 	}
 
+	bot.watchDependencies(h, checks)
+
 	return nil
 }
 
+// mergeCheck is the result of evaluating one of the merge conditions.
+type mergeCheck struct {
+	name   string
+	passed bool
+	detail string
+
+	// pending means the condition may be met later without any event of gitee,
+	// such as the pending status checks.
+	pending bool
+
+	// err is the error of evaluating the condition. It is logged instead of being shown
+	// in the detail, because the error of api may have the url with the token.
+	err error
+}
+
+// isWaitingForMergeWindow checks whether the merge window is the only failed condition.
+func isWaitingForMergeWindow(checks []mergeCheck) bool {
+	waiting := false
+
+	for i := range checks {
+		if item := &checks[i]; !item.passed {
+			if item.name != checkNameMergeWindow {
+				return false
+			}
+
+			waiting = true
+		}
+	}
+
+	return waiting
+}
+
+// isWaitingForStatusChecks checks whether pr can be merged, or queued for the merge window,
+// once the pending status checks succeed.
+func isWaitingForStatusChecks(checks []mergeCheck) bool {
+	waiting := false
+
+	for i := range checks {
+		item := &checks[i]
+
+		switch {
+		case item.passed || item.name == checkNameMergeWindow:
+		case item.name == checkNameStatusChecks && item.pending:
+			waiting = true
+		default:
+			return false
+		}
+	}
+
+	return waiting
+}
+
+func isAllPassed(checks []mergeCheck) bool {
+	for i := range checks {
+		if !checks[i].passed {
+			return false
+		}
+	}
+
+	return true
+}
+
 type mergeHelper struct {
 	pr  *sdk.PullRequestHook
 	cfg *botConfig
@@ -93,7 +231,65 @@ type mergeHelper struct {
 	repo    string
 	trigger string
 
-	cli iClient
+	cli      iClient
+	cacheCli *cache.SDK
+	botLogin string
+
+	// freeze caches the content of freeze files.
+	freeze *freezeCache
+
+	// teams caches the members of teams which are the owners of freeze.
+	teams *teamCache
+
+	// waitingSince is when pr started waiting for the pending status checks,
+	// zero if it is not re-checked from the merge queue.
+	waitingSince time.Time
+
+	// commits caches the commits of pr, because several conditions need them.
+	commits []sdk.PullRequestCommits
+
+	// comments caches the comments of pr, which are checked for the approvals of bot.
+	comments []sdk.PullRequestComments
+}
+
+func (m *mergeHelper) ownerSources() ownerSources {
+	return ownerSources{files: m.cacheCli, teams: m.teams, cli: m.cli}
+}
+
+func (m *mergeHelper) getComments() ([]sdk.PullRequestComments, error) {
+	if m.comments != nil {
+		return m.comments, nil
+	}
+
+	v, err := m.cli.ListPRComments(m.org, m.repo, m.pr.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	if v == nil {
+		v = []sdk.PullRequestComments{}
+	}
+	m.comments = v
+
+	return v, nil
+}
+
+func (m *mergeHelper) getCommits() ([]sdk.PullRequestCommits, error) {
+	if m.commits != nil {
+		return m.commits, nil
+	}
+
+	v, err := m.cli.GetPRCommits(m.org, m.repo, m.pr.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	if v == nil {
+		v = []sdk.PullRequestCommits{}
+	}
+	m.commits = v
+
+	return v, nil
 }
 
 func (m *mergeHelper) merge() error {
@@ -114,110 +310,437 @@ func (m *mergeHelper) merge() error {
 	return m.cli.MergePR(
 		m.org, m.repo, number,
 		sdk.PullRequestMergePutParam{
-			MergeMethod: string(m.cfg.MergeMethod),
+			MergeMethod:       string(m.cfg.MergeMethod),
+			PruneSourceBranch: m.canPruneSourceBranch(),
 		},
 	)
 }
 
-func (m *mergeHelper) canMerge() ([]string, bool) {
-	if !m.pr.GetMergeable() {
-		return []string{msgPRConflicts}, false
+// canPruneSourceBranch checks whether the source branch can be deleted after merging.
+// It never deletes the protected branch or the branch of fork.
+func (m *mergeHelper) canPruneSourceBranch() bool {
+	if !m.cfg.PruneSourceBranch {
+		return false
+	}
+
+	head, base := m.pr.GetHead(), m.pr.GetBase()
+	if head.GetRepo() == nil || base.GetRepo() == nil ||
+		head.GetRepo().FullName != base.GetRepo().FullName ||
+		head.GetRef() == base.GetRef() {
+		return false
 	}
 
+	branches, err := m.cli.GetRepoAllBranch(m.org, m.repo)
+	if err != nil {
+		return false
+	}
+
+	for i := range branches {
+		if b := &branches[i]; b.Name == head.GetRef() {
+			return !b.Protected
+		}
+	}
+
+	return false
+}
+
+// canMerge evaluates every merge condition and returns the result of each one,
+// so that all the missing conditions can be shown at once.
+func (m *mergeHelper) canMerge() ([]mergeCheck, bool) {
 	labels := sets.NewString()
 	for _, item := range m.pr.Labels {
 		labels.Insert(item.Name)
 	}
 
-	if r := isLabelMatched(labels, m.cfg); len(r) > 0 {
-		return r, false
+	checks := []mergeCheck{m.checkConflict(), checkWorkInProgress(m.pr)}
+
+	if needsCheckingTargetBranch(m.cfg) {
+		checks = append(checks, m.checkTargetBranch())
+	}
+
+	lgtmCount := m.cfg.LgtmCountsRequired
+	if m.cfg.Size != nil {
+		if lines, class, err := m.getSize(); err != nil {
+			checks = append(checks, mergeCheck{
+				name:   checkNameSize,
+				detail: msgFailedToGetSize,
+				err:    err,
+			})
+		} else {
+			lgtmCount = m.cfg.lgtmCountsRequired(class)
+			checks = append(checks, m.checkSize(lines, class))
+		}
+	}
+
+	checks = append(checks, isLabelMatched(m.dropUntrustedLabels(labels), m.cfg, lgtmCount)...)
+
+	for _, item := range m.cfg.getBackportApprovals(m.pr.GetBase().GetRef()) {
+		checks = append(checks, m.checkBackportApproval(item, labels))
+	}
+
+	if m.cfg.CheckDCO {
+		checks = append(checks, m.checkDCO())
+	}
+
+	if needsCheckingCommits(m.cfg) {
+		checks = append(checks, m.checkCommits())
+	}
+
+	if m.cfg.Lint != nil {
+		checks = append(checks, m.checkLint())
+	}
+
+	if m.cfg.RequireLinkedIssue {
+		checks = append(checks, m.checkLinkedIssue())
+	}
+
+	if len(m.cfg.RequiredStatusChecks) > 0 {
+		checks = append(checks, m.checkStatuses())
+	}
+
+	if deps := parseDependencies(m.pr.Body); len(deps) > 0 {
+		checks = append(checks, m.checkDependencies(deps))
+	}
+
+	checks = append(checks, m.checkFreeze(labels))
+
+	if m.cfg.MergeWindows != nil {
+		checks = append(checks, m.checkMergeWindow(time.Now()))
+	}
+
+	return checks, isAllPassed(checks)
+}
+
+func (m *mergeHelper) checkConflict() mergeCheck {
+	if !m.pr.GetMergeable() {
+		return mergeCheck{name: checkNameConflict, detail: msgPRConflicts}
+	}
+
+	return mergeCheck{name: checkNameConflict, passed: true, detail: msgPRNoConflicts}
+}
+
+func (m *mergeHelper) checkStatuses() mergeCheck {
+	r := mergeCheck{name: checkNameStatusChecks}
+
+	statuses, err := m.cli.ListCommitStatuses(m.org, m.repo, m.pr.GetHead().GetSha())
+	if err != nil {
+		r.detail = msgFailedToGetStatuses
+		r.err = err
+
+		return r
+	}
+
+	// the statuses are sorted from new to old, so only the first one of each context is valid.
+	states := map[string]string{}
+	for i := range statuses {
+		if item := &statuses[i]; states[item.Context] == "" {
+			states[item.Context] = item.State
+		}
+	}
+
+	var unsuccessful []string
+	r.pending = true
+	for _, ctx := range m.cfg.RequiredStatusChecks {
+		if state := states[ctx]; state != statusSuccess {
+			if state == "" {
+				state = "missing"
+			} else if state != statusPending {
+				r.pending = false
+			}
+
+			unsuccessful = append(unsuccessful, fmt.Sprintf("%s(%s)", ctx, state))
+		}
+	}
+
+	if len(unsuccessful) > 0 {
+		r.detail = fmt.Sprintf(msgStatusesNotSuccessful, strings.Join(unsuccessful, ", "))
+	} else {
+		r.passed = true
+		r.pending = false
+		r.detail = fmt.Sprintf(msgStatusesSuccessful, strings.Join(m.cfg.RequiredStatusChecks, ", "))
 	}
 
+	return r
+}
+
+func (m *mergeHelper) checkFreeze(labels sets.String) mergeCheck {
+	r := mergeCheck{name: checkNameFreeze}
+
 	freeze, err := m.getFreezeInfo()
 	if err != nil {
-		return nil, false
+		r.detail = msgFailedToGetFreeze
+		r.err = err
+
+		return r
+	}
+
+	now := time.Now()
+
+	if freeze == nil || !freeze.isFrozenAt(now) {
+		r.passed = true
+		r.detail = msgBranchNotFrozen
+
+		if freeze != nil {
+			if at, ok := freeze.nextFreeze(now); ok {
+				r.detail = fmt.Sprintf(msgBranchWillFreeze, at.Format(mergeWindowTimeFormat))
+			}
+		}
+
+		return r
+	}
+
+	approved, err := m.isFreezeExceptionApproved(labels)
+	if err != nil {
+		r.detail = msgFailedToCheckFreezeException
+		r.err = err
+
+		return r
 	}
 
-	if freeze == nil || !freeze.isFrozen() {
-		return nil, true
+	if approved {
+		r.passed = true
+		r.detail = fmt.Sprintf(msgFreezeRuleMatched, msgFreezeExceptionApproved, freeze.String())
+
+		return r
 	}
 
+	owners := strings.Join(freeze.Owner, ", ")
+	if !freeze.Frozen && !freeze.end.IsZero() {
+		r.detail = fmt.Sprintf(msgFrozenUntil, freeze.end.Format(mergeWindowTimeFormat), owners)
+	} else {
+		r.detail = fmt.Sprintf(msgFrozenWithOwner, owners)
+	}
+	r.detail = fmt.Sprintf(msgFreezeRuleMatched, r.detail, freeze.String())
+
 	if m.trigger == "" {
-		return nil, false
+		return r
+	}
+
+	if r.passed, err = freeze.isOwner(m.ownerSources(), m.trigger); err != nil {
+		r.detail = fmt.Sprintf(msgFailedToGetFreezeOwners, r.detail)
+		r.err = err
+	}
+
+	return r
+}
+
+func (m *mergeHelper) checkMergeWindow(now time.Time) mergeCheck {
+	w := m.cfg.MergeWindows
+	if w.isOpen(now) {
+		return mergeCheck{name: checkNameMergeWindow, passed: true, detail: msgMergeWindowOpen}
 	}
 
-	if freeze.isOwner(m.trigger) {
-		return nil, true
+	r := mergeCheck{name: checkNameMergeWindow}
+
+	if at, ok := w.nextOpen(now); ok {
+		r.detail = fmt.Sprintf(msgMergeWindowClosed, at.Format(mergeWindowTimeFormat))
+	} else {
+		r.detail = fmt.Sprintf(msgMergeWindowNotOpen, maxDaysToFindMergeWindow)
 	}
 
-	return []string{
-		fmt.Sprintf(msgFrozenWithOwner, strings.Join(freeze.Owner, ", ")),
-	}, false
+	return r
 }
 
-func (m *mergeHelper) getFreezeInfo() (*freezeItem, error) {
+// freezeRule is the freeze item matched and the file where it is defined.
+type freezeRule struct {
+	freezeItem
+
+	file  freezeFile
+	match freezeMatch
+}
+
+func (r *freezeRule) String() string {
+	return fmt.Sprintf("%s in %s", r.describe(), r.file.String())
+}
+
+// isOwner checks whether the login is one of the owners of freeze whose references,
+// such as 'sig:Kernel', are expanded.
+func (r *freezeRule) isOwner(src ownerSources, login string) (bool, error) {
+	owners, err := expandOwnerRefs(src, r.Owner, r.file)
+	if owners.Has(strings.ToLower(login)) {
+		return true, nil
+	}
+
+	return false, err
+}
+
+// getFreezeInfo returns the most specific freeze rule of all the freeze files
+// which matches the target branch of pr.
+func (m *mergeHelper) getFreezeInfo() (*freezeRule, error) {
 	branch := m.pr.GetBase().GetRef()
-	for _, v := range m.cfg.FreezeFile {
-		fc, err := m.getFreezeContent(v)
+
+	var r *freezeRule
+	for _, f := range m.cfg.FreezeFile {
+		fc, err := m.getFreezeContent(f)
 		if err != nil {
 			return nil, err
 		}
 
-		if v := fc.getFreezeItem(m.org, branch); v != nil {
-			return v, nil
+		v, match := fc.getFreezeItem(m.org, branch)
+		if v == nil || (r != nil && !match.moreSpecificThan(r.match)) {
+			continue
 		}
+
+		r = &freezeRule{freezeItem: *v, file: f, match: match}
+	}
+
+	if r == nil {
+		return nil, nil
 	}
 
-	return nil, nil
+	if err := r.parseSchedule(); err != nil {
+		return nil, fmt.Errorf("the freeze rule of %s is invalid, %s", r.String(), err.Error())
+	}
+
+	return r, nil
 }
 
 func (m *mergeHelper) getFreezeContent(f freezeFile) (freezeContent, error) {
-	var fc freezeContent
+	if m.freeze == nil {
+		return loadFreezeFile(f, m.cli)
+	}
 
-	c, err := m.cli.GetPathContent(f.Owner, f.Repo, f.Branch, f.Path)
-	if err != nil {
-		return fc, err
+	return m.freeze.get(f, m.cli)
+}
+
+// isLabelMatched checks the labels of pr. The lgtmCount is the number of lgtm labels required.
+func isLabelMatched(labels sets.String, cfg *botConfig, lgtmCount uint) []mergeCheck {
+	var checks []mergeCheck
+
+	if ln := lgtmCount; cfg.maxLgtmCountsRequired() == 1 {
+		checks = append(checks, checkLabelsExist(checkNameLGTM, labels, lgtmLabel))
+	} else {
+		n := uint(len(getLGTMLabelsOnPR(labels)))
+		checks = append(checks, mergeCheck{
+			name:   checkNameLGTM,
+			passed: n >= ln,
+			detail: fmt.Sprintf(msgNotEnoughLGTMLabel, ln, n),
+		})
 	}
 
-	b, err := base64.StdEncoding.DecodeString(c.Content)
-	if err != nil {
-		return fc, err
+	checks = append(checks, checkLabelsExist(checkNameApproved, labels, approvedLabel))
+
+	if len(cfg.LabelsForMerge) > 0 {
+		checks = append(checks, checkLabelsExist(
+			checkNameRequiredLabels, labels, cfg.LabelsForMerge...,
+		))
+	}
+
+	if len(cfg.MissingLabelsForMerge) > 0 {
+		checks = append(checks, checkLabelsMissing(labels, cfg.MissingLabelsForMerge))
 	}
 
-	err = yaml.Unmarshal(b, &fc)
+	for _, e := range cfg.labelExprs {
+		r := mergeCheck{name: checkNameLabelExpression, passed: true}
 
-	return fc, err
+		if e.eval(labels) {
+			r.detail = fmt.Sprintf(msgLabelExprSatisfied, e.String())
+		} else {
+			r.passed = false
+			r.detail = fmt.Sprintf(msgLabelExprNotSatisfied, e.String(), e.failed(labels).String())
+		}
+
+		checks = append(checks, r)
+	}
+
+	return checks
 }
 
-func isLabelMatched(labels sets.String, cfg *botConfig) []string {
-	var reasons []string
+func checkLabelsMissing(labels sets.String, patterns []string) mergeCheck {
+	r := mergeCheck{name: checkNameForbiddenLabels, passed: true}
 
-	needs := sets.NewString(approvedLabel)
-	needs.Insert(cfg.LabelsForMerge...)
+	invalid := sets.NewString()
+	for _, p := range patterns {
+		operand := labelOperand{pattern: p}
 
-	if ln := cfg.LgtmCountsRequired; ln == 1 {
-		needs.Insert(lgtmLabel)
-	} else {
-		v := getLGTMLabelsOnPR(labels)
-		if n := uint(len(v)); n < ln {
-			reasons = append(reasons, fmt.Sprintf(msgNotEnoughLGTMLabel, ln, n))
+		for l := range labels {
+			if operand.match(l) {
+				invalid.Insert(l)
+			}
 		}
 	}
 
-	if v := needs.Difference(labels); v.Len() > 0 {
-		reasons = append(reasons, fmt.Sprintf(
-			msgMissingLabels, strings.Join(v.UnsortedList(), ", "),
-		))
+	if invalid.Len() > 0 {
+		r.passed = false
+		r.detail = fmt.Sprintf(msgInvalidLabels, strings.Join(invalid.List(), ", "))
+	} else {
+		r.detail = fmt.Sprintf(msgNoInvalidLabels, strings.Join(patterns, ", "))
 	}
 
-	if len(cfg.MissingLabelsForMerge) > 0 {
-		missing := sets.NewString(cfg.MissingLabelsForMerge...)
-		if v := missing.Intersection(labels); v.Len() > 0 {
-			reasons = append(reasons, fmt.Sprintf(
-				msgInvalidLabels, strings.Join(v.UnsortedList(), ", "),
-			))
+	return r
+}
+
+func checkLabelsExist(name string, labels sets.String, needs ...string) mergeCheck {
+	r := mergeCheck{name: name, passed: true}
+
+	if v := sets.NewString(needs...).Difference(labels); v.Len() > 0 {
+		r.passed = false
+		r.detail = fmt.Sprintf(msgMissingLabels, strings.Join(v.List(), ", "))
+	} else {
+		r.detail = fmt.Sprintf(msgHasLabels, strings.Join(needs, ", "))
+	}
+
+	return r
+}
+
+// newPRHook converts the pull request got by api to the one of webhook event.
+func newPRHook(pr *sdk.PullRequest) *sdk.PullRequestHook {
+	labels := make([]sdk.LabelHook, len(pr.Labels))
+	for i := range pr.Labels {
+		labels[i] = sdk.LabelHook{Id: pr.Labels[i].Id, Name: pr.Labels[i].Name}
+	}
+
+	assignees := make([]sdk.UserHook, len(pr.Assignees))
+	for i := range pr.Assignees {
+		assignees[i] = sdk.UserHook{Login: pr.Assignees[i].Login}
+	}
+
+	v := &sdk.PullRequestHook{
+		Id:         pr.Id,
+		Number:     pr.Number,
+		State:      pr.State,
+		HtmlUrl:    pr.HtmlUrl,
+		Title:      pr.Title,
+		Body:       pr.Body,
+		Labels:     labels,
+		Assignees:  assignees,
+		Mergeable:  pr.Mergeable,
+		NeedReview: pr.NeedReview,
+		NeedTest:   pr.NeedTest,
+		Draft:      pr.Draft,
+		Head:       newBranchHook(pr.Head),
+		Base:       newBranchHook(pr.Base),
+	}
+
+	if pr.User != nil {
+		v.User = &sdk.UserHook{Login: pr.User.Login}
+	}
+
+	return v
+}
+
+func newBranchHook(b *sdk.BranchHead) *sdk.BranchHook {
+	if b == nil {
+		return nil
+	}
+
+	v := &sdk.BranchHook{Label: b.Label, Ref: b.Ref, Sha: b.Sha}
+
+	if b.User != nil {
+		v.User = &sdk.UserHook{Login: b.User.Login}
+	}
+
+	if b.Repo != nil {
+		v.Repo = &sdk.ProjectHook{
+			Name:     b.Repo.Name,
+			Path:     b.Repo.Path,
+			FullName: b.Repo.FullName,
+		}
+
+		if i := strings.Index(b.Repo.FullName, "/"); i > 0 {
+			v.Repo.Namespace = b.Repo.FullName[:i]
 		}
 	}
 
-	return reasons
+	return v
 }
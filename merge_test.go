@@ -0,0 +1,112 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeStatusClient lists the statuses of commit. The other methods of iClient are not implemented.
+type fakeStatusClient struct {
+	iClient
+
+	statuses []commitStatus
+}
+
+func (c *fakeStatusClient) ListCommitStatuses(org, repo, ref string) ([]commitStatus, error) {
+	return c.statuses, nil
+}
+
+func TestStatusRecheck(t *testing.T) {
+	status := func(ctx, state string) commitStatus {
+		return commitStatus{Context: ctx, State: state}
+	}
+	failed := mergeCheck{name: checkNameLGTM}
+	windowClosed := mergeCheck{name: checkNameMergeWindow}
+
+	cases := []struct {
+		name     string
+		statuses []commitStatus
+		others   []mergeCheck
+		// waiting is whether pr is re-checked later.
+		waiting bool
+	}{
+		{"successful", []commitStatus{status("ci", "success")}, nil, false},
+		{"pending", []commitStatus{status("ci", "pending")}, nil, true},
+		{"missing", nil, nil, true},
+		{"latest is pending", []commitStatus{status("ci", "pending"), status("ci", "failure")}, nil, true},
+		{"failed", []commitStatus{status("ci", "failure")}, nil, false},
+		{"pending and failed", []commitStatus{status("ci", "pending"), status("build", "error")}, nil, false},
+		{"merge window is closed", []commitStatus{status("ci", "pending")}, []mergeCheck{windowClosed}, true},
+		{"other condition is not met", []commitStatus{status("ci", "pending")}, []mergeCheck{failed}, false},
+	}
+
+	for _, c := range cases {
+		h := mergeHelper{
+			pr:  &sdk.PullRequestHook{Number: 1, Head: &sdk.BranchHook{Sha: "sha"}},
+			cfg: &botConfig{RequiredStatusChecks: []string{"ci", "build"}},
+			cli: &fakeStatusClient{statuses: append(c.statuses, status("build", "success"))},
+		}
+
+		checks := append([]mergeCheck{h.checkStatuses()}, c.others...)
+
+		if v := isWaitingForStatusChecks(checks); v != c.waiting {
+			t.Errorf("%s: got waiting=%t, want %t", c.name, v, c.waiting)
+		}
+	}
+}
+
+func TestQueueStatusRecheck(t *testing.T) {
+	store, err := newStateStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bot := &robot{queue: newMergeQueue(store, func(queuedMerge) {})}
+	log := logrus.NewEntry(logrus.New())
+	now := time.Now()
+
+	cases := []struct {
+		name         string
+		waitingSince time.Time
+		queued       bool
+	}{
+		{"first time", time.Time{}, true},
+		{"still pending", now.Add(-time.Hour), true},
+		{"timeout", now.Add(-statusRecheckTimeout - time.Minute), false},
+	}
+
+	for i, c := range cases {
+		h := mergeHelper{
+			pr:           &sdk.PullRequestHook{Number: int32(i + 1)},
+			org:          "org",
+			repo:         "repo",
+			waitingSince: c.waitingSince,
+		}
+
+		bot.queueStatusRecheck(&h, log)
+
+		item, ok := bot.queue.items[prKey{org: "org", repo: "repo", number: h.pr.Number}.String()]
+		if ok != c.queued {
+			t.Errorf("%s: got queued=%t, want %t", c.name, ok, c.queued)
+
+			continue
+		}
+
+		if !ok {
+			continue
+		}
+
+		if item.WaitingSince.IsZero() || (!c.waitingSince.IsZero() && !item.WaitingSince.Equal(c.waitingSince)) {
+			t.Errorf("%s: unexpected waiting since: %s", c.name, item.WaitingSince)
+		}
+
+		if d := time.Until(item.At); d <= 0 || d > statusRecheckInterval {
+			t.Errorf("%s: unexpected time to re-check: %s", c.name, item.At)
+		}
+
+		bot.queue.timers[item.key().String()].Stop()
+	}
+}
@@ -0,0 +1,507 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	mergeWindowTimeFormat = "2006-01-02 15:04 MST"
+	absoluteTimeFormat    = "2006-01-02T15:04"
+
+	// maxDaysToFindMergeWindow is the limit of days to find the next opening of merge window.
+	maxDaysToFindMergeWindow = 60
+)
+
+var (
+	regWeeklyRange   = regexp.MustCompile(`^(\S+)\s+(\d{2}):(\d{2})-(\d{2}):(\d{2})$`)
+	regAbsoluteRange = regexp.MustCompile(`^(\S+)/(\S+)$`)
+
+	weekdays = map[string]time.Weekday{
+		"sun": time.Sunday,
+		"mon": time.Monday,
+		"tue": time.Tuesday,
+		"wed": time.Wednesday,
+		"thu": time.Thursday,
+		"fri": time.Friday,
+		"sat": time.Saturday,
+	}
+)
+
+// mergeWindows specifies when the PR can be merged automatically.
+// Each item of Allow and Deny is either a weekly range, such as 'Mon-Fri 09:00-18:00',
+// '* 00:00-24:00' or 'Sat,Sun 10:00-12:00', or an absolute range, such as
+// '2022-03-26T00:00/2022-03-28T00:00'.
+type mergeWindows struct {
+	// Timezone is the time zone of the ranges, such as Asia/Shanghai. The default is UTC.
+	Timezone string `json:"timezone,omitempty"`
+
+	// Allow specifies the ranges in which PR can be merged.
+	// PR can be merged at any time except the denied ranges if it is empty.
+	Allow []string `json:"allow,omitempty"`
+
+	// Deny specifies the ranges in which PR can't be merged. It has higher priority than Allow.
+	Deny []string `json:"deny,omitempty"`
+
+	loc   *time.Location
+	allow []timeRange
+	deny  []timeRange
+}
+
+func (w *mergeWindows) validate() error {
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return fmt.Errorf("invalid timezone of merge windows: %s", err.Error())
+	}
+	w.loc = loc
+
+	if w.allow, err = parseTimeRanges(w.Allow, loc); err != nil {
+		return err
+	}
+
+	w.deny, err = parseTimeRanges(w.Deny, loc)
+
+	return err
+}
+
+func (w *mergeWindows) isOpen(t time.Time) bool {
+	t = t.In(w.loc)
+
+	for _, r := range w.deny {
+		if r.contains(t) {
+			return false
+		}
+	}
+
+	if len(w.allow) == 0 {
+		return true
+	}
+
+	for _, r := range w.allow {
+		if r.contains(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nextOpen returns the first time at which the window is open since t. The window can only
+// be opened at a boundary of the ranges or a change of the offset of time zone, so it is
+// enough to check them in order.
+func (w *mergeWindows) nextOpen(t time.Time) (time.Time, bool) {
+	t = t.In(w.loc)
+
+	if w.isOpen(t) {
+		return t, true
+	}
+
+	end := t.AddDate(0, 0, maxDaysToFindMergeWindow)
+
+	for _, v := range w.boundaries(t, end) {
+		if w.isOpen(v) {
+			return v, true
+		}
+	}
+
+	return end, false
+}
+
+// boundaries returns the sorted times in (from, to) at which the window may be opened or closed.
+func (w *mergeWindows) boundaries(from, to time.Time) []time.Time {
+	var r []time.Time
+
+	add := func(v time.Time) {
+		if v.After(from) && v.Before(to) {
+			r = append(r, v)
+		}
+	}
+
+	var weekly []weeklyRange
+	for _, items := range [][]timeRange{w.allow, w.deny} {
+		for _, item := range items {
+			switch v := item.(type) {
+			case weeklyRange:
+				weekly = append(weekly, v)
+			case absoluteRange:
+				add(v.start)
+				add(v.end)
+			}
+		}
+	}
+
+	y, m, d := from.Date()
+	for day := time.Date(y, m, d, 0, 0, 0, 0, w.loc); day.Before(to); day = day.AddDate(0, 0, 1) {
+		y, m, d := day.Date()
+
+		for _, v := range weekly {
+			if v.days[day.Weekday()] {
+				// the minutes of 24:00 is normalized to the next day.
+				add(time.Date(y, m, d, 0, v.start, 0, 0, w.loc))
+				add(time.Date(y, m, d, 0, v.end, 0, 0, w.loc))
+			}
+		}
+
+		// the wall clock jumps at the change of offset, such as the start or end of DST.
+		next := day.AddDate(0, 0, 1)
+		_, o1 := day.Zone()
+		_, o2 := next.Zone()
+		if o1 != o2 {
+			add(zoneTransition(day, next))
+		}
+	}
+
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].Before(r[j])
+	})
+
+	return r
+}
+
+// zoneTransition returns the time in (lo, hi] at which the offset of time zone is changed.
+func zoneTransition(lo, hi time.Time) time.Time {
+	_, offset := lo.Zone()
+
+	for hi.Sub(lo) > time.Second {
+		mid := lo.Add(hi.Sub(lo) / 2)
+		if _, o := mid.Zone(); o == offset {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	return hi
+}
+
+type timeRange interface {
+	contains(t time.Time) bool
+}
+
+type weeklyRange struct {
+	days [7]bool
+
+	// start and end are the minutes of the day.
+	start int
+	end   int
+}
+
+func (r weeklyRange) contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+
+	return r.days[t.Weekday()] && m >= r.start && m < r.end
+}
+
+type absoluteRange struct {
+	start time.Time
+	end   time.Time
+}
+
+func (r absoluteRange) contains(t time.Time) bool {
+	return !t.Before(r.start) && t.Before(r.end)
+}
+
+func parseTimeRanges(items []string, loc *time.Location) ([]timeRange, error) {
+	r := make([]timeRange, 0, len(items))
+
+	for _, item := range items {
+		s := strings.TrimSpace(item)
+
+		if m := regWeeklyRange.FindStringSubmatch(s); m != nil {
+			v, err := parseWeeklyRange(m)
+			if err != nil {
+				return nil, fmt.Errorf("invalid range of merge windows: %s, %s", item, err.Error())
+			}
+
+			r = append(r, v)
+
+			continue
+		}
+
+		if m := regAbsoluteRange.FindStringSubmatch(s); m != nil {
+			v, err := parseAbsoluteRange(m, loc)
+			if err != nil {
+				return nil, fmt.Errorf("invalid range of merge windows: %s, %s", item, err.Error())
+			}
+
+			r = append(r, v)
+
+			continue
+		}
+
+		return nil, fmt.Errorf("invalid range of merge windows: %s", item)
+	}
+
+	return r, nil
+}
+
+func parseWeeklyRange(m []string) (weeklyRange, error) {
+	var r weeklyRange
+
+	if err := parseWeekdays(m[1], &r.days); err != nil {
+		return r, err
+	}
+
+	var err error
+	if r.start, err = parseMinutes(m[2], m[3]); err != nil {
+		return r, err
+	}
+
+	if r.end, err = parseMinutes(m[4], m[5]); err != nil {
+		return r, err
+	}
+
+	if r.start >= r.end {
+		return r, fmt.Errorf("the start time must be before the end time")
+	}
+
+	return r, nil
+}
+
+func parseWeekdays(s string, days *[7]bool) error {
+	if s == "*" {
+		for i := range days {
+			days[i] = true
+		}
+
+		return nil
+	}
+
+	for _, item := range strings.Split(strings.ToLower(s), ",") {
+		v := strings.Split(item, "-")
+		if len(v) > 2 {
+			return fmt.Errorf("invalid days: %s", item)
+		}
+
+		start, ok := weekdays[v[0]]
+		if !ok {
+			return fmt.Errorf("invalid day: %s", v[0])
+		}
+
+		end := start
+		if len(v) == 2 {
+			if end, ok = weekdays[v[1]]; !ok {
+				return fmt.Errorf("invalid day: %s", v[1])
+			}
+		}
+
+		for d := start; ; d = (d + 1) % 7 {
+			days[d] = true
+
+			if d == end {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+func parseMinutes(hour, minute string) (int, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(hour+":"+minute, "%d:%d", &h, &m); err != nil {
+		return 0, err
+	}
+
+	if m > 59 || h > 24 || (h == 24 && m > 0) {
+		return 0, fmt.Errorf("invalid time: %s:%s", hour, minute)
+	}
+
+	return h*60 + m, nil
+}
+
+func parseAbsoluteRange(m []string, loc *time.Location) (absoluteRange, error) {
+	var r absoluteRange
+	var err error
+
+	if r.start, err = time.ParseInLocation(absoluteTimeFormat, m[1], loc); err != nil {
+		return r, err
+	}
+
+	if r.end, err = time.ParseInLocation(absoluteTimeFormat, m[2], loc); err != nil {
+		return r, err
+	}
+
+	if !r.start.Before(r.end) {
+		return r, fmt.Errorf("the start time must be before the end time")
+	}
+
+	return r, nil
+}
+
+const (
+	stateMergeQueue = "merge_queue"
+
+	// retryQueuedMergeInterval is the interval to retry the merge restored from the state
+	// before the config is received with any webhook event after the bot restarts.
+	retryQueuedMergeInterval = time.Minute
+
+	// statusRecheckInterval is the interval to re-check the pending status checks,
+	// because gitee does not deliver the event of commit status.
+	statusRecheckInterval = 5 * time.Minute
+
+	// statusRecheckTimeout is how long the pending status checks are re-checked,
+	// so a status check which never finishes is not polled forever.
+	statusRecheckTimeout = 24 * time.Hour
+)
+
+// queuedMerge is a pr which waits for the opening of merge window or the pending status checks.
+type queuedMerge struct {
+	Org     string    `json:"org"`
+	Repo    string    `json:"repo"`
+	Number  int32     `json:"number"`
+	Trigger string    `json:"trigger,omitempty"`
+	At      time.Time `json:"at"`
+
+	// WaitingSince is when pr started waiting for the pending status checks.
+	WaitingSince time.Time `json:"waiting_since,omitempty"`
+}
+
+func (q queuedMerge) key() prKey {
+	return prKey{org: q.Org, repo: q.Repo, number: q.Number}
+}
+
+// mergeQueue holds the PRs which are ready to merge but wait for the opening of merge window
+// or the pending status checks.
+// The PRs are persisted in the state store, so they are queued again after the bot restarts.
+type mergeQueue struct {
+	lock   sync.Mutex
+	timers map[string]*time.Timer
+	items  map[string]queuedMerge
+	store  *stateStore
+	merge  func(queuedMerge)
+}
+
+func newMergeQueue(store *stateStore, merge func(queuedMerge)) *mergeQueue {
+	return &mergeQueue{
+		timers: map[string]*time.Timer{},
+		items:  map[string]queuedMerge{},
+		store:  store,
+		merge:  merge,
+	}
+}
+
+// restore queues the PRs saved before the bot restarts.
+func (q *mergeQueue) restore() error {
+	var items map[string]queuedMerge
+	if err := q.store.load(stateMergeQueue, &items); err != nil {
+		return err
+	}
+
+	for _, v := range items {
+		q.add(v)
+	}
+
+	return nil
+}
+
+// add schedules the merge of pr at the time. The previous one of the same pr will be replaced.
+func (q *mergeQueue) add(item queuedMerge) {
+	key := item.key().String()
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if t, ok := q.timers[key]; ok {
+		t.Stop()
+	}
+
+	var t *time.Timer
+	t = time.AfterFunc(time.Until(item.At), func() {
+		q.lock.Lock()
+		// the timer may be replaced after it fires.
+		if q.timers[key] == t {
+			delete(q.timers, key)
+			delete(q.items, key)
+			q.save()
+		}
+		q.lock.Unlock()
+
+		q.merge(item)
+	})
+
+	q.timers[key] = t
+	q.items[key] = item
+	q.save()
+}
+
+// save must be called with the lock held.
+func (q *mergeQueue) save() {
+	if err := q.store.save(stateMergeQueue, q.items); err != nil {
+		logrus.WithError(err).Error("save the merge queue")
+	}
+}
+
+func (bot *robot) queueMerge(h *mergeHelper, log *logrus.Entry) {
+	at, ok := h.cfg.MergeWindows.nextOpen(time.Now())
+	if !ok {
+		return
+	}
+
+	item := queuedMerge{
+		Org:     h.org,
+		Repo:    h.repo,
+		Number:  h.pr.Number,
+		Trigger: h.trigger,
+		At:      at,
+	}
+
+	log.Infof("queue %s to merge at %s", item.key(), at.Format(mergeWindowTimeFormat))
+
+	bot.queue.add(item)
+}
+
+// queueStatusRecheck re-evaluates pr later when it waits for the pending status checks
+// only, until they finish or the statusRecheckTimeout is reached.
+func (bot *robot) queueStatusRecheck(h *mergeHelper, log *logrus.Entry) {
+	now := time.Now()
+
+	since := h.waitingSince
+	if since.IsZero() {
+		since = now
+	} else if now.Sub(since) > statusRecheckTimeout {
+		log.Infof("the status checks have been pending since %s, stop re-checking them", since.Format(time.RFC3339))
+
+		return
+	}
+
+	bot.queue.add(queuedMerge{
+		Org:          h.org,
+		Repo:         h.repo,
+		Number:       h.pr.Number,
+		Trigger:      h.trigger,
+		At:           now.Add(statusRecheckInterval),
+		WaitingSince: since,
+	})
+}
+
+// mergeQueued re-evaluates the queued pr with the latest config, because the one it was
+// queued with may be changed or lost after the bot restarts.
+func (bot *robot) mergeQueued(item queuedMerge) {
+	k := item.key()
+	log := logrus.WithField("pr", k.String())
+
+	cfg, ok := bot.latestConfig(k.org, k.repo)
+	if !ok {
+		log.Info("the config is not received yet, retry the queued merge later")
+
+		item.At = time.Now().Add(retryQueuedMergeInterval)
+		bot.queue.add(item)
+
+		return
+	}
+
+	if cfg == nil {
+		log.Info("the repo is not configured any more, drop the queued merge")
+
+		return
+	}
+
+	bot.evaluateQueued(item, cfg, log)
+}
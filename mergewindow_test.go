@@ -0,0 +1,122 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeWindowsNextOpen(t *testing.T) {
+	cases := []struct {
+		name     string
+		timezone string
+		allow    []string
+		deny     []string
+		// from and want are in RFC3339. want is empty if the window never opens.
+		from string
+		want string
+	}{
+		{
+			name:  "open now",
+			allow: []string{"Mon-Fri 09:00-18:00"},
+			from:  "2022-03-21T10:00:00Z",
+			want:  "2022-03-21T10:00:00Z",
+		},
+		{
+			name:  "later of the day",
+			allow: []string{"Mon-Fri 09:00-18:00"},
+			from:  "2022-03-21T08:30:20Z",
+			want:  "2022-03-21T09:00:00Z",
+		},
+		{
+			name:  "weekday wrap-around",
+			allow: []string{"Fri-Mon 09:00-18:00"},
+			from:  "2022-03-22T10:00:00Z",
+			want:  "2022-03-25T09:00:00Z",
+		},
+		{
+			name:  "wrap-around of week",
+			allow: []string{"Sat,Sun 10:00-12:00"},
+			from:  "2022-03-27T13:00:00Z",
+			want:  "2022-04-02T10:00:00Z",
+		},
+		{
+			name:  "open until 24:00",
+			allow: []string{"Mon 20:00-24:00"},
+			from:  "2022-03-21T23:59:00Z",
+			want:  "2022-03-21T23:59:00Z",
+		},
+		{
+			name:  "closed since 24:00",
+			allow: []string{"Mon 20:00-24:00"},
+			from:  "2022-03-22T00:00:00Z",
+			want:  "2022-03-28T20:00:00Z",
+		},
+		{
+			name: "open after 24:00 of deny",
+			deny: []string{"Mon 00:00-24:00"},
+			from: "2022-03-21T12:00:00Z",
+			want: "2022-03-22T00:00:00Z",
+		},
+		{
+			name:  "absolute deny",
+			allow: []string{"* 00:00-24:00"},
+			deny:  []string{"2022-03-26T00:00/2022-03-28T09:30"},
+			from:  "2022-03-26T10:00:00Z",
+			want:  "2022-03-28T09:30:00Z",
+		},
+		{
+			name:     "timezone",
+			timezone: "Asia/Shanghai",
+			allow:    []string{"Mon-Fri 09:00-18:00"},
+			from:     "2022-03-20T23:00:00Z",
+			want:     "2022-03-21T01:00:00Z",
+		},
+		{
+			name:     "start of DST skips the start of range",
+			timezone: "America/New_York",
+			allow:    []string{"Sun 02:30-03:30"},
+			from:     "2022-03-13T05:00:00Z",
+			want:     "2022-03-13T07:00:00Z",
+		},
+		{
+			name:     "end of DST repeats the range",
+			timezone: "America/New_York",
+			allow:    []string{"Sun 01:00-01:30"},
+			from:     "2022-11-06T05:45:00Z",
+			want:     "2022-11-06T06:00:00Z",
+		},
+		{
+			name:  "never open",
+			allow: []string{"2021-01-01T00:00/2021-01-02T00:00"},
+			from:  "2022-03-21T00:00:00Z",
+		},
+	}
+
+	for _, c := range cases {
+		w := mergeWindows{Timezone: c.timezone, Allow: c.allow, Deny: c.deny}
+		if err := w.validate(); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		from, err := time.Parse(time.RFC3339, c.from)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		v, ok := w.nextOpen(from)
+
+		if c.want == "" {
+			if ok {
+				t.Errorf("%s: expected never open, got %s", c.name, v.UTC().Format(time.RFC3339))
+			}
+
+			continue
+		}
+
+		if !ok {
+			t.Errorf("%s: expected open at %s, got never", c.name, c.want)
+		} else if got := v.UTC().Format(time.RFC3339); got != c.want {
+			t.Errorf("%s: got %s, want %s", c.name, got, c.want)
+		}
+	}
+}
@@ -2,17 +2,35 @@ package main
 
 import (
 	"encoding/base64"
+	"fmt"
+	"path"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/opensourceways/community-robot-lib/giteeclient"
+	"github.com/opensourceways/community-robot-lib/utils"
 	"github.com/opensourceways/repo-file-cache/models"
+	cache "github.com/opensourceways/repo-file-cache/sdk"
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/yaml"
 )
 
-const ownerFile = "OWNERS"
+const (
+	ownerFile        = "OWNERS"
+	ownerAliasesFile = "OWNERS_ALIASES"
+	defaultSigsDir   = "sig"
+
+	ownerRefSig   = "sig:"
+	ownerRefAlias = "alias:"
+	ownerRefRepo  = "repo:"
+	ownerRefTeam  = "team:"
+
+	commentNoPermissionForCmd = `***@%s*** has no permission to use the command of ***/%s*** in this pull request. :astonished:
+Please contact to the collaborators in this repository.`
+)
 
 func (bot *robot) hasPermission(
 	commenter string,
@@ -132,13 +150,20 @@ func (bot *robot) getSigOwnerFiles(org, repo, branch string, log *logrus.Entry)
 }
 
 func decodeOwnerFile(content string, log *logrus.Entry) sets.String {
+	owners, err := parseOwnerFile(content)
+	if err != nil {
+		log.WithError(err).Error("decode owner file")
+	}
+
+	return owners
+}
+
+func parseOwnerFile(content string) (sets.String, error) {
 	owners := sets.NewString()
 
 	c, err := base64.StdEncoding.DecodeString(content)
 	if err != nil {
-		log.WithError(err).Error("decode file")
-
-		return owners
+		return owners, err
 	}
 
 	var m struct {
@@ -147,9 +172,7 @@ func decodeOwnerFile(content string, log *logrus.Entry) sets.String {
 	}
 
 	if err = yaml.Unmarshal(c, &m); err != nil {
-		log.WithError(err).Error("code yaml file")
-
-		return owners
+		return owners, err
 	}
 
 	for _, v := range m.Maintainers {
@@ -160,5 +183,221 @@ func decodeOwnerFile(content string, log *logrus.Entry) sets.String {
 		owners.Insert(strings.ToLower(v))
 	}
 
-	return owners
+	return owners, nil
+}
+
+// ownerSources are where the references of owners are resolved from.
+type ownerSources struct {
+	files *cache.SDK
+	teams *teamCache
+	cli   iClient
+}
+
+func (bot *robot) ownerSources() ownerSources {
+	return ownerSources{files: bot.cacheCli, teams: bot.teamCache, cli: bot.cli}
+}
+
+// expandOwnerRefs resolves the owners which may be the references as below to logins.
+//
+//	sig:Kernel              the OWNERS of sig in the sigs dir of the repo storing the freeze file
+//	alias:release-managers  the alias in the OWNERS_ALIASES of the repo storing the freeze file
+//	repo:org/repo           the OWNERS in the root of repo on the owners_branch of freeze file
+//	team:org/slug           the members of the team of org
+//
+// The files are read from the repo file cache and the members of team are cached by the
+// teamCache. The owners resolved are returned even if some references can't be resolved.
+func expandOwnerRefs(src ownerSources, refs []string, f freezeFile) (sets.String, error) {
+	owners := sets.NewString()
+	merr := utils.NewMultiErrors()
+	files := cachedFiles{cli: src.files}
+
+	var aliases map[string][]string
+
+	for _, ref := range refs {
+		switch {
+		case strings.HasPrefix(ref, ownerRefSig):
+			p := path.Join(f.sigsDir(), strings.TrimPrefix(ref, ownerRefSig), ownerFile)
+
+			v, err := files.getOwners(f.Owner, f.Repo, f.Branch, p)
+			if err != nil {
+				merr.AddError(err)
+			}
+			owners = owners.Union(v)
+
+		case strings.HasPrefix(ref, ownerRefAlias):
+			if aliases == nil {
+				v, err := files.getAliases(f.Owner, f.Repo, f.Branch)
+				if err != nil {
+					merr.AddError(err)
+
+					continue
+				}
+				aliases = v
+			}
+
+			for _, v := range aliases[strings.TrimPrefix(ref, ownerRefAlias)] {
+				owners.Insert(strings.ToLower(v))
+			}
+
+		case strings.HasPrefix(ref, ownerRefRepo):
+			v := strings.Split(strings.TrimPrefix(ref, ownerRefRepo), "/")
+			if len(v) != 2 {
+				merr.AddError(fmt.Errorf("invalid owner reference: %s", ref))
+
+				continue
+			}
+
+			o, err := files.getOwners(v[0], v[1], f.ownersBranch(), ownerFile)
+			if err != nil {
+				merr.AddError(err)
+			}
+			owners = owners.Union(o)
+
+		case strings.HasPrefix(ref, ownerRefTeam):
+			v := strings.Split(strings.TrimPrefix(ref, ownerRefTeam), "/")
+			if len(v) != 2 {
+				merr.AddError(fmt.Errorf("invalid owner reference: %s", ref))
+
+				continue
+			}
+
+			o, err := src.teams.get(v[0], v[1], src.cli)
+			if err != nil {
+				merr.AddError(err)
+
+				continue
+			}
+			owners = owners.Union(o)
+
+		default:
+			owners.Insert(strings.ToLower(ref))
+		}
+	}
+
+	return owners, merr.Err()
+}
+
+// cachedFiles gets the files from the repo file cache. It keeps the files got
+// by their names, so every kind of file is got once in each branch.
+type cachedFiles struct {
+	cli   *cache.SDK
+	files map[models.Branch]map[string]models.FilesInfo
+}
+
+// get returns the content of file which is the path in the branch of repo.
+func (c *cachedFiles) get(org, repo, branch, file string) (string, error) {
+	b := models.Branch{
+		Platform: "gitee",
+		Org:      org,
+		Repo:     repo,
+		Branch:   branch,
+	}
+	name := path.Base(file)
+
+	if c.files == nil {
+		c.files = map[models.Branch]map[string]models.FilesInfo{}
+	}
+	if c.files[b] == nil {
+		c.files[b] = map[string]models.FilesInfo{}
+	}
+
+	info, ok := c.files[b][name]
+	if !ok {
+		v, err := c.cli.GetFiles(b, name, false)
+		if err != nil {
+			return "", fmt.Errorf("get file:%s/%s/%s:%s, err:%s", org, repo, branch, file, err.Error())
+		}
+
+		info = v
+		c.files[b][name] = v
+	}
+
+	for i := range info.Files {
+		if strings.TrimPrefix(string(info.Files[i].Path), "/") == file {
+			return info.Files[i].Content, nil
+		}
+	}
+
+	return "", fmt.Errorf("file:%s/%s/%s:%s is not found in the cache", org, repo, branch, file)
+}
+
+func (c *cachedFiles) getOwners(org, repo, branch, file string) (sets.String, error) {
+	content, err := c.get(org, repo, branch, file)
+	if err != nil {
+		return sets.NewString(), err
+	}
+
+	return parseOwnerFile(content)
+}
+
+func (c *cachedFiles) getAliases(org, repo, branch string) (map[string][]string, error) {
+	content, err := c.get(org, repo, branch, ownerAliasesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var m struct {
+		Aliases map[string][]string `json:"aliases"`
+	}
+
+	if err = yaml.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+
+	return m.Aliases, nil
+}
+
+type teamCacheItem struct {
+	members sets.String
+	expiry  time.Time
+}
+
+// teamCache caches the members of org teams until they expire, because they are
+// resolved whenever the owners of a frozen branch are checked.
+type teamCache struct {
+	lock  sync.RWMutex
+	ttl   time.Duration
+	items map[string]teamCacheItem
+}
+
+func newTeamCache(ttl time.Duration) *teamCache {
+	return &teamCache{
+		ttl:   ttl,
+		items: make(map[string]teamCacheItem),
+	}
+}
+
+// get returns the logins of the members of team in lower case.
+func (c *teamCache) get(org, slug string, cli iClient) (sets.String, error) {
+	key := org + "/" + slug
+	now := time.Now()
+
+	c.lock.RLock()
+	item, ok := c.items[key]
+	c.lock.RUnlock()
+
+	if ok && now.Before(item.expiry) {
+		return item.members, nil
+	}
+
+	v, err := cli.ListTeamMembers(org, slug)
+	if err != nil {
+		return nil, fmt.Errorf("get members of team:%s, err:%s", key, err.Error())
+	}
+
+	members := sets.NewString()
+	for i := range v {
+		members.Insert(strings.ToLower(v[i].Login))
+	}
+
+	c.lock.Lock()
+	c.items[key] = teamCacheItem{members: members, expiry: now.Add(c.ttl)}
+	c.lock.Unlock()
+
+	return members, nil
 }
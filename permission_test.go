@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+)
+
+// fakeTeamClient lists the members of teams and counts the calls.
+// The other methods of iClient are not implemented.
+type fakeTeamClient struct {
+	iClient
+
+	teams map[string][]string
+	calls int
+}
+
+func (c *fakeTeamClient) ListTeamMembers(org, slug string) ([]sdk.UserBasic, error) {
+	c.calls++
+
+	logins, ok := c.teams[org+"/"+slug]
+	if !ok {
+		return nil, errors.New("404 not found")
+	}
+
+	r := make([]sdk.UserBasic, len(logins))
+	for i, v := range logins {
+		r[i] = sdk.UserBasic{Login: v}
+	}
+
+	return r, nil
+}
+
+func TestExpandTeamOwnerRefs(t *testing.T) {
+	cli := &fakeTeamClient{teams: map[string][]string{
+		"openeuler/release": {"Alice", "bob"},
+	}}
+	src := ownerSources{teams: newTeamCache(time.Hour), cli: cli}
+
+	cases := []struct {
+		refs   []string
+		owners []string
+		err    bool
+	}{
+		{[]string{"team:openeuler/release"}, []string{"alice", "bob"}, false},
+		{[]string{"team:openeuler/release", "Carol"}, []string{"alice", "bob", "carol"}, false},
+		{[]string{"team:openeuler/unknown", "carol"}, []string{"carol"}, true},
+		{[]string{"team:release", "carol"}, []string{"carol"}, true},
+	}
+
+	for _, c := range cases {
+		owners, err := expandOwnerRefs(src, c.refs, freezeFile{})
+		if (err != nil) != c.err {
+			t.Errorf("expand %v: unexpected error: %v", c.refs, err)
+		}
+
+		if v := owners.List(); !reflect.DeepEqual(v, c.owners) {
+			t.Errorf("expand %v: got %v, want %v", c.refs, v, c.owners)
+		}
+	}
+
+	// the members of team are listed once for the first two cases and the errors are not cached.
+	if cli.calls != 2 {
+		t.Errorf("expected the team members to be listed 2 times, got %d", cli.calls)
+	}
+}
+
+func TestTeamCacheExpiry(t *testing.T) {
+	cli := &fakeTeamClient{teams: map[string][]string{"openeuler/release": {"alice"}}}
+	c := newTeamCache(time.Hour)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.get("openeuler", "release", cli); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if cli.calls != 1 {
+		t.Fatalf("expected the cached members to be used, got %d calls", cli.calls)
+	}
+
+	cli.teams["openeuler/release"] = []string{"bob"}
+	c.items["openeuler/release"] = teamCacheItem{expiry: time.Now().Add(-time.Second)}
+
+	v, err := c.get("openeuler", "release", cli)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cli.calls != 2 || !reflect.DeepEqual(v.List(), []string{"bob"}) {
+		t.Errorf("expected the expired members to be listed again, got %v after %d calls", v.List(), cli.calls)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/opensourceways/community-robot-lib/giteeclient"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	needsRebaseLabel = "needs-rebase"
+
+	// mergeableCheckDelay is the time to wait for gitee to compute whether the pr is mergeable.
+	mergeableCheckDelay = time.Minute
+
+	msgNeedsRebase = "@%s , this pr conflicts with the target branch ***%s*** now, please rebase it. The ***%s*** label will be removed once it is mergeable again."
+)
+
+// handleNeedsRebase manages the needs-rebase label when the pr is opened or its branches change.
+func (bot *robot) handleNeedsRebase(e *sdk.PullRequestEvent, log *logrus.Entry) error {
+	switch giteeclient.GetPullRequestAction(e) {
+	case giteeclient.PRActionOpened,
+		giteeclient.PRActionChangedSourceBranch,
+		giteeclient.PRActionChangedTargetBranch:
+	default:
+		return nil
+	}
+
+	pr := giteeclient.GetPRInfoByPREvent(e)
+	bot.updateNeedsRebaseLabelLater(pr.Org, pr.Repo, []int32{pr.Number}, log)
+
+	return nil
+}
+
+// handleRebaseAfterMerge checks the other open prs to the same target branch
+// after a pr is merged, because they may conflict with it.
+func (bot *robot) handleRebaseAfterMerge(e *sdk.PullRequestEvent, log *logrus.Entry) error {
+	if e.Action == nil || *e.Action != prActionMerge {
+		return nil
+	}
+
+	org, repo := giteeclient.GetOwnerAndRepoByPREvent(e)
+
+	prs, err := bot.cli.GetPullRequests(org, repo, giteeclient.ListPullRequestOpt{
+		State: prStateOpen,
+		Base:  e.GetPullRequest().GetBase().GetRef(),
+	})
+	if err != nil {
+		return err
+	}
+
+	numbers := make([]int32, len(prs))
+	for i := range prs {
+		numbers[i] = prs[i].Number
+	}
+
+	bot.updateNeedsRebaseLabelLater(org, repo, numbers, log)
+
+	return nil
+}
+
+// updateNeedsRebaseLabelLater updates the needs-rebase label of the prs by their latest
+// state after a while. Gitee computes whether a pr is mergeable asynchronously, so the
+// one delivered with the webhook event is stale when the pr or its target branch changes.
+func (bot *robot) updateNeedsRebaseLabelLater(org, repo string, numbers []int32, log *logrus.Entry) {
+	if len(numbers) == 0 {
+		return
+	}
+
+	time.AfterFunc(mergeableCheckDelay, func() {
+		for _, n := range numbers {
+			pr, err := bot.cli.GetGiteePullRequest(org, repo, n)
+			if err != nil {
+				log.WithError(err).Errorf("get pr %s/%s#%d", org, repo, n)
+
+				continue
+			}
+
+			if pr.State != prStateOpen {
+				continue
+			}
+
+			if err := bot.updateNeedsRebaseLabel(newPRInfo(org, repo, &pr), pr.Mergeable, log); err != nil {
+				log.WithError(err).Errorf("update the %s label of pr %s/%s#%d", needsRebaseLabel, org, repo, n)
+			}
+		}
+	})
+}
+
+func newPRInfo(org, repo string, pr *sdk.PullRequest) giteeclient.PRInfo {
+	labels := sets.NewString()
+	for _, l := range pr.Labels {
+		labels.Insert(l.Name)
+	}
+
+	info := giteeclient.PRInfo{
+		Org:    org,
+		Repo:   repo,
+		Number: pr.Number,
+		Labels: labels,
+	}
+
+	if pr.Base != nil {
+		info.BaseRef = pr.Base.Ref
+	}
+
+	if pr.User != nil {
+		info.Author = pr.User.Login
+	}
+
+	return info
+}
+
+func (bot *robot) updateNeedsRebaseLabel(pr giteeclient.PRInfo, mergeable bool, log *logrus.Entry) error {
+	org, repo, number := pr.Org, pr.Repo, pr.Number
+
+	if mergeable {
+		if pr.Labels.Has(needsRebaseLabel) {
+			return bot.cli.RemovePRLabel(org, repo, number, needsRebaseLabel)
+		}
+
+		return nil
+	}
+
+	if pr.Labels.Has(needsRebaseLabel) {
+		return nil
+	}
+
+	if err := bot.createLabelIfNeed(org, repo, needsRebaseLabel); err != nil {
+		log.WithError(err).Errorf("create repo label: %s", needsRebaseLabel)
+	}
+
+	if err := bot.cli.AddPRLabel(org, repo, number, needsRebaseLabel); err != nil {
+		return err
+	}
+
+	return bot.cli.CreatePRComment(
+		org, repo, number,
+		fmt.Sprintf(msgNeedsRebase, pr.Author, pr.BaseRef, needsRebaseLabel),
+	)
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"sync/atomic"
+	"time"
 
 	sdk "gitee.com/openeuler/go-gitee/gitee"
 	libconfig "github.com/opensourceways/community-robot-lib/config"
@@ -19,22 +21,65 @@ type iClient interface {
 	RemovePRLabel(org, repo string, number int32, label string) error
 	RemovePRLabels(org, repo string, number int32, label []string) error
 	CreatePRComment(org, repo string, number int32, comment string) error
+	UpdatePRComment(org, repo string, commentID int32, comment string) error
+	DeletePRComment(org, repo string, ID int32) error
+	ListPRComments(org, repo string, number int32) ([]sdk.PullRequestComments, error)
 	GetUserPermissionsOfRepo(org, repo, login string) (sdk.ProjectMemberPermission, error)
 	GetPathContent(org, repo, path, ref string) (sdk.Content, error)
 	GetPullRequestChanges(org, repo string, number int32) ([]sdk.PullRequestFiles, error)
 	CreateRepoLabel(org, repo, label, color string) error
 	GetRepoLabels(owner, repo string) ([]sdk.Label, error)
 	MergePR(owner, repo string, number int32, opt sdk.PullRequestMergePutParam) error
+	GetRepoAllBranch(org, repo string) ([]sdk.Branch, error)
 	UpdatePullRequest(org, repo string, number int32, param sdk.PullRequestUpdateParam) (sdk.PullRequest, error)
+	GetGiteePullRequest(org, repo string, number int32) (sdk.PullRequest, error)
+	GetPullRequests(org, repo string, opts giteeclient.ListPullRequestOpt) ([]sdk.PullRequest, error)
+	GetPRCommits(org, repo string, number int32) ([]sdk.PullRequestCommits, error)
+	CreatePullRequest(org, repo, title, body, head, base string, canModify bool) (sdk.PullRequest, error)
+	ListCommitStatuses(org, repo, ref string) ([]commitStatus, error)
+	GetOrgIssue(org, number string) (sdk.Issue, error)
+	ListTeamMembers(org, slug string) ([]sdk.UserBasic, error)
+	CreateIssueComment(org, repo string, number string, comment string) error
+	UpdateIssue(owner, number string, param sdk.IssueUpdateParam) (sdk.Issue, error)
 }
 
-func newRobot(cli iClient, cacheCli *cache.SDK) *robot {
-	return &robot{cli: cli, cacheCli: cacheCli}
+func newRobot(
+	cli iClient, cacheCli *cache.SDK, git *gitClient, botLogin string,
+	freezeCacheTTL time.Duration, store *stateStore,
+) *robot {
+	bot := &robot{
+		cli:          cli,
+		botLogin:     botLogin,
+		cacheCli:     cacheCli,
+		git:          git,
+		dependencies: newDependencyIndex(),
+		freezeCache:  newFreezeCache(freezeCacheTTL, store),
+		teamCache:    newTeamCache(freezeCacheTTL),
+		freezeSignal: make(chan struct{}, 1),
+	}
+
+	bot.queue = newMergeQueue(store, bot.mergeQueued)
+
+	return bot
 }
 
 type robot struct {
 	cli      iClient
+	botLogin string
 	cacheCli *cache.SDK
+	queue    *mergeQueue
+	git      *gitClient
+
+	dependencies *dependencyIndex
+	freezeCache  *freezeCache
+	teamCache    *teamCache
+
+	// freezeSignal asks the freeze watcher to sync the freeze states.
+	freezeSignal chan struct{}
+
+	// config is the configuration received with the last webhook event. It is used by
+	// the jobs which are not triggered by webhook, such as the queued merges.
+	config atomic.Value
 }
 
 func (bot *robot) NewPluginConfig() libconfig.PluginConfig {
@@ -47,6 +92,8 @@ func (bot *robot) getConfig(cfg libconfig.PluginConfig, org, repo string) (*botC
 		return nil, fmt.Errorf("can't convert to configuration")
 	}
 
+	bot.config.Store(c)
+
 	if bc := c.configFor(org, repo); bc != nil {
 		return bc, nil
 	}
@@ -54,9 +101,21 @@ func (bot *robot) getConfig(cfg libconfig.PluginConfig, org, repo string) (*botC
 	return nil, fmt.Errorf("no config for this repo:%s/%s", org, repo)
 }
 
+// latestConfig returns the config of repo in the configuration received with the last
+// webhook event. It returns false if none is received since the bot starts.
+func (bot *robot) latestConfig(org, repo string) (*botConfig, bool) {
+	c, ok := bot.config.Load().(*configuration)
+	if !ok {
+		return nil, false
+	}
+
+	return c.configFor(org, repo), true
+}
+
 func (bot *robot) RegisterEventHandler(p libplugin.HandlerRegitster) {
 	p.RegisterPullRequestHandler(bot.handlePREvent)
 	p.RegisterNoteEventHandler(bot.handleNoteEvent)
+	p.RegisterPushEventHandler(bot.handlePushEvent)
 }
 
 func (bot *robot) handlePREvent(e *sdk.PullRequestEvent, pc libconfig.PluginConfig, log *logrus.Entry) error {
@@ -79,7 +138,51 @@ func (bot *robot) handlePREvent(e *sdk.PullRequestEvent, pc libconfig.PluginConf
 		merr.AddError(err)
 	}
 
-	if err := bot.handleLabelUpdate(e, cfg); err != nil {
+	if err := bot.handleTargetBranch(e, cfg, log); err != nil {
+		merr.AddError(err)
+	}
+
+	if err := bot.handleWorkInProgress(e, cfg, log); err != nil {
+		merr.AddError(err)
+	}
+
+	if err := bot.handleDCO(e, cfg, log); err != nil {
+		merr.AddError(err)
+	}
+
+	if err := bot.handleFreezeExceptionReset(e, log); err != nil {
+		merr.AddError(err)
+	}
+
+	if err := bot.handleBackportApprovalReset(e, cfg); err != nil {
+		merr.AddError(err)
+	}
+
+	if err := bot.handleNeedsSquash(e, cfg, log); err != nil {
+		merr.AddError(err)
+	}
+
+	if err := bot.handleSizeLabel(e, cfg, log); err != nil {
+		merr.AddError(err)
+	}
+
+	if err := bot.handlePRUpdate(e, cfg, log); err != nil {
+		merr.AddError(err)
+	}
+
+	if err := bot.handleDependencyMerged(e, log); err != nil {
+		merr.AddError(err)
+	}
+
+	if err := bot.handleNeedsRebase(e, log); err != nil {
+		merr.AddError(err)
+	}
+
+	if err := bot.handleRebaseAfterMerge(e, log); err != nil {
+		merr.AddError(err)
+	}
+
+	if err := bot.handleCherryPickAfterMerge(e, cfg, log); err != nil {
 		merr.AddError(err)
 	}
 
@@ -102,9 +205,44 @@ func (bot *robot) handleNoteEvent(e *sdk.NoteEvent, pc libconfig.PluginConfig, l
 		merr.AddError(err)
 	}
 
-	if err = bot.handleCheckPR(e, cfg); err != nil {
+	if err = bot.handleLifecycle(e, cfg, log); err != nil {
+		merr.AddError(err)
+	}
+
+	if err = bot.handleCheckPR(e, cfg, log); err != nil {
+		merr.AddError(err)
+	}
+
+	if err = bot.handleCherryPick(e, cfg, log); err != nil {
+		merr.AddError(err)
+	}
+
+	if err = bot.handleLint(e, cfg, log); err != nil {
+		merr.AddError(err)
+	}
+
+	if err = bot.handleFreezeException(e, cfg, log); err != nil {
+		merr.AddError(err)
+	}
+
+	if err = bot.handleFreezeStatus(e, cfg, log); err != nil {
+		merr.AddError(err)
+	}
+
+	if err = bot.handleBackportApprove(e, cfg, log); err != nil {
 		merr.AddError(err)
 	}
 
 	return merr.Err()
 }
+
+func (bot *robot) handlePushEvent(e *sdk.PushEvent, pc libconfig.PluginConfig, log *logrus.Entry) error {
+	c, ok := pc.(*configuration)
+	if !ok {
+		return fmt.Errorf("can't convert to configuration")
+	}
+
+	bot.config.Store(c)
+
+	return bot.handleFreezeFilePush(e, c, log)
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/opensourceways/community-robot-lib/giteeclient"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	sizeLabelPrefix = "size/"
+
+	checkNameSize = "Size"
+
+	msgSizeExceeded    = "PR changes %d lines and its size is %s which exceeds %s."
+	msgSizeNotExceeded = "PR changes %d lines and its size is %s."
+	msgFailedToGetSize = "Failed to get the changes of PR."
+)
+
+// sizeClass is the class of pr size by the count of changed lines.
+type sizeClass struct {
+	name string
+
+	// max is the exclusive upper limit of changed lines. The last class has no limit.
+	max int
+}
+
+var sizeClasses = []sizeClass{
+	{name: "XS", max: 10},
+	{name: "S", max: 30},
+	{name: "M", max: 100},
+	{name: "L", max: 500},
+	{name: "XL", max: 1000},
+	{name: "XXL"},
+}
+
+func getSizeClass(lines int) int {
+	for i, c := range sizeClasses {
+		if c.max == 0 || lines < c.max {
+			return i
+		}
+	}
+
+	return len(sizeClasses) - 1
+}
+
+func findSizeClass(name string) int {
+	for i, c := range sizeClasses {
+		if strings.EqualFold(c.name, name) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func sizeLabel(class int) string {
+	return sizeLabelPrefix + sizeClasses[class].name
+}
+
+// sizeConfig specifies how to compute the size of PR and the requirements depending on it.
+// The size classes are XS(<10), S(<30), M(<100), L(<500), XL(<1000) and XXL by the count
+// of added and deleted lines.
+type sizeConfig struct {
+	// ExcludedPaths are the glob patterns of files which are not counted, such as
+	// generated files. The pattern ending with '/' excludes all the files under the directory.
+	ExcludedPaths []string `json:"excluded_paths,omitempty"`
+
+	// LgtmCountsRequired specifies the number of lgtm labels required by size class,
+	// such as 'XL: 2'. It takes effect only when it is greater than the lgtm_counts_required.
+	LgtmCountsRequired map[string]uint `json:"lgtm_counts_required,omitempty"`
+
+	// MaxSize is the maximum size class of PR which can be merged. It is not limited if it is empty.
+	MaxSize  string `json:"max_size,omitempty"`
+	maxClass int
+}
+
+func (c *sizeConfig) validate() error {
+	for k := range c.LgtmCountsRequired {
+		if findSizeClass(k) < 0 {
+			return fmt.Errorf("unknown size class: %s", k)
+		}
+	}
+
+	for _, p := range c.ExcludedPaths {
+		if err := validatePattern(p); err != nil {
+			return fmt.Errorf("invalid excluded path: %s", p)
+		}
+	}
+
+	c.maxClass = len(sizeClasses) - 1
+	if c.MaxSize != "" {
+		if c.maxClass = findSizeClass(c.MaxSize); c.maxClass < 0 {
+			return fmt.Errorf("unknown size class: %s", c.MaxSize)
+		}
+	}
+
+	return nil
+}
+
+func (c *sizeConfig) isExcluded(file string) bool {
+	for _, p := range c.ExcludedPaths {
+		if strings.HasSuffix(p, "/") && strings.HasPrefix(file, p) {
+			return true
+		}
+
+		if ok, _ := path.Match(p, file); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *sizeConfig) countLines(changes []sdk.PullRequestFiles) int {
+	n := 0
+
+	for i := range changes {
+		f := &changes[i]
+		if c.isExcluded(f.Filename) {
+			continue
+		}
+
+		a, _ := strconv.Atoi(f.Additions)
+		d, _ := strconv.Atoi(f.Deletions)
+		n += a + d
+	}
+
+	return n
+}
+
+// lgtmCountsRequired returns the number of lgtm labels required for the size class.
+func (c *botConfig) lgtmCountsRequired(class int) uint {
+	n := c.LgtmCountsRequired
+
+	if c.Size != nil {
+		for k, v := range c.Size.LgtmCountsRequired {
+			if findSizeClass(k) == class && v > n {
+				n = v
+			}
+		}
+	}
+
+	return n
+}
+
+// maxLgtmCountsRequired returns the maximum number of lgtm labels which may be required.
+// The lgtm label is composed of 'lgtm-login' when it is greater than 1.
+func (c *botConfig) maxLgtmCountsRequired() uint {
+	n := c.LgtmCountsRequired
+
+	if c.Size != nil {
+		for _, v := range c.Size.LgtmCountsRequired {
+			if v > n {
+				n = v
+			}
+		}
+	}
+
+	return n
+}
+
+// getSize returns the count of changed lines and the size class of pr.
+func (m *mergeHelper) getSize() (int, int, error) {
+	changes, err := m.cli.GetPullRequestChanges(m.org, m.repo, m.pr.Number)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	n := m.cfg.Size.countLines(changes)
+
+	return n, getSizeClass(n), nil
+}
+
+func (m *mergeHelper) checkSize(lines, class int) mergeCheck {
+	r := mergeCheck{name: checkNameSize}
+
+	if limit := m.cfg.Size.maxClass; class > limit {
+		r.detail = fmt.Sprintf(msgSizeExceeded, lines, sizeClasses[class].name, sizeClasses[limit].name)
+	} else {
+		r.passed = true
+		r.detail = fmt.Sprintf(msgSizeNotExceeded, lines, sizeClasses[class].name)
+	}
+
+	return r
+}
+
+// handleSizeLabel applies the size label when the pr is opened or its commits change.
+func (bot *robot) handleSizeLabel(e *sdk.PullRequestEvent, cfg *botConfig, log *logrus.Entry) error {
+	if cfg.Size == nil {
+		return nil
+	}
+
+	if a := giteeclient.GetPullRequestAction(e); a != giteeclient.PRActionOpened &&
+		a != giteeclient.PRActionChangedSourceBranch {
+		return nil
+	}
+
+	pr := giteeclient.GetPRInfoByPREvent(e)
+
+	changes, err := bot.cli.GetPullRequestChanges(pr.Org, pr.Repo, pr.Number)
+	if err != nil {
+		return err
+	}
+
+	label := sizeLabel(getSizeClass(cfg.Size.countLines(changes)))
+
+	var obsolete []string
+	for l := range pr.Labels {
+		if strings.HasPrefix(l, sizeLabelPrefix) && l != label {
+			obsolete = append(obsolete, l)
+		}
+	}
+
+	if len(obsolete) > 0 {
+		if err := bot.cli.RemovePRLabels(pr.Org, pr.Repo, pr.Number, obsolete); err != nil {
+			return err
+		}
+	}
+
+	if pr.Labels.Has(label) {
+		return nil
+	}
+
+	if err := bot.createLabelIfNeed(pr.Org, pr.Repo, label); err != nil {
+		log.WithError(err).Errorf("create repo label: %s", label)
+	}
+
+	return bot.cli.AddPRLabel(pr.Org, pr.Repo, pr.Number, label)
+}
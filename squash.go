@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/opensourceways/community-robot-lib/giteeclient"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	needsSquashLabel = "needs-squash"
+
+	checkNameCommits = "Commits"
+
+	msgTooManyCommits     = "PR has %d commits which exceeds %d, please squash them."
+	msgCommitsWithinLimit = "PR has %d commits which does not exceed %d."
+	msgFailedToGetCommits = "Failed to get the commits of PR."
+)
+
+// needsCheckingCommits checks whether the count of commits is limited. It is
+// not limited when the pr will be squash-merged.
+func needsCheckingCommits(cfg *botConfig) bool {
+	return cfg.MaxCommits > 0 && cfg.MergeMethod != mergeMethodSquash
+}
+
+func (m *mergeHelper) checkCommits() mergeCheck {
+	r := mergeCheck{name: checkNameCommits}
+
+	commits, err := m.getCommits()
+	if err != nil {
+		r.detail = msgFailedToGetCommits
+		r.err = err
+
+		return r
+	}
+
+	n, limit := len(commits), m.cfg.MaxCommits
+	if n > limit {
+		r.detail = fmt.Sprintf(msgTooManyCommits, n, limit)
+	} else {
+		r.passed = true
+		r.detail = fmt.Sprintf(msgCommitsWithinLimit, n, limit)
+	}
+
+	return r
+}
+
+// handleNeedsSquash manages the needs-squash label when the pr is opened or
+// the author pushes to the source branch.
+func (bot *robot) handleNeedsSquash(e *sdk.PullRequestEvent, cfg *botConfig, log *logrus.Entry) error {
+	if !needsCheckingCommits(cfg) {
+		return nil
+	}
+
+	if a := giteeclient.GetPullRequestAction(e); a != giteeclient.PRActionOpened &&
+		a != giteeclient.PRActionChangedSourceBranch {
+		return nil
+	}
+
+	pr := giteeclient.GetPRInfoByPREvent(e)
+
+	commits, err := bot.cli.GetPRCommits(pr.Org, pr.Repo, pr.Number)
+	if err != nil {
+		return err
+	}
+
+	hasLabel := pr.Labels.Has(needsSquashLabel)
+
+	if len(commits) <= cfg.MaxCommits {
+		if hasLabel {
+			return bot.cli.RemovePRLabel(pr.Org, pr.Repo, pr.Number, needsSquashLabel)
+		}
+
+		return nil
+	}
+
+	if hasLabel {
+		return nil
+	}
+
+	if err := bot.createLabelIfNeed(pr.Org, pr.Repo, needsSquashLabel); err != nil {
+		log.WithError(err).Errorf("create repo label: %s", needsSquashLabel)
+	}
+
+	return bot.cli.AddPRLabel(pr.Org, pr.Repo, pr.Number, needsSquashLabel)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// stateStore persists the state of bot which must survive a restart, such as the queued merges.
+// Each kind of state is a section of a JSON file. It is kept in memory only if the file is not set.
+type stateStore struct {
+	lock     sync.Mutex
+	path     string
+	sections map[string]json.RawMessage
+}
+
+func newStateStore(path string) (*stateStore, error) {
+	s := &stateStore{path: path, sections: map[string]json.RawMessage{}}
+	if path == "" {
+		return s, nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+
+		return nil, err
+	}
+
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &s.sections); err != nil {
+			return nil, fmt.Errorf("failed to parse the state file %s, %s", path, err.Error())
+		}
+	}
+
+	return s, nil
+}
+
+// load decodes the section into v. v is untouched if the section does not exist.
+func (s *stateStore) load(section string, v interface{}) error {
+	s.lock.Lock()
+	b, ok := s.sections[section]
+	s.lock.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// save replaces the section with v and writes the whole state to the file.
+func (s *stateStore) save(section string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.sections[section] = b
+
+	if s.path == "" {
+		return nil
+	}
+
+	if b, err = json.Marshal(s.sections); err != nil {
+		return err
+	}
+
+	// write a temporary file and rename it, so the file is never left half written.
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/opensourceways/community-robot-lib/giteeclient"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// statusCommentMark is used to find the status comment of bot in the comments of pr.
+	statusCommentMark = "<!-- openeuler-review: merge status -->"
+
+	statusCommentTitle = "**Merge status of this pull request:**"
+	msgNotMergeable    = "@%s , this pr is not mergeable and the reasons are the failed conditions above."
+
+	markPassed = "✅"
+	markFailed = "❌"
+)
+
+func (bot *robot) notifyInStatusComment(e giteeclient.PRNoteEvent, cfg *botConfig, notice string) error {
+	org, repo := e.GetOrgRep()
+
+	h := mergeHelper{
+		cfg:      cfg,
+		org:      org,
+		repo:     repo,
+		cli:      bot.cli,
+		cacheCli: bot.cacheCli,
+		botLogin: bot.botLogin,
+		freeze:   bot.freezeCache,
+		teams:    bot.teamCache,
+		pr:       e.GetPullRequest(),
+		trigger:  e.GetCommenter(),
+	}
+
+	checks, _ := h.canMerge()
+
+	return bot.updateStatusComment(&h, checks, notice)
+}
+
+// updateStatusComment edits the status comment of bot in place or creates it
+// if it does not exist, so that there is only one status comment for each pr.
+// The status comment is re-created when there is a notice, because the users
+// mentioned in an edited comment are not notified.
+func (bot *robot) updateStatusComment(h *mergeHelper, checks []mergeCheck, notice string) error {
+	body := genStatusComment(checks, notice)
+	number := h.pr.Number
+
+	for i := range checks {
+		if item := &checks[i]; item.err != nil {
+			logrus.WithError(item.err).Errorf("check %s of %s/%s#%d", item.name, h.org, h.repo, number)
+		}
+	}
+
+	comments, err := bot.cli.ListPRComments(h.org, h.repo, number)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range comments {
+		if !bot.isStatusComment(&c) {
+			continue
+		}
+
+		if notice != "" {
+			if err := bot.cli.DeletePRComment(h.org, h.repo, c.Id); err != nil {
+				return err
+			}
+
+			break
+		}
+
+		if c.Body == body {
+			return nil
+		}
+
+		return bot.cli.UpdatePRComment(h.org, h.repo, c.Id, body)
+	}
+
+	return bot.cli.CreatePRComment(h.org, h.repo, number, body)
+}
+
+// isStatusComment checks whether the comment is the status comment created by bot,
+// because anyone can post a comment starting with the mark.
+func (bot *robot) isStatusComment(c *sdk.PullRequestComments) bool {
+	return c.User != nil &&
+		strings.EqualFold(c.User.Login, bot.botLogin) &&
+		strings.HasPrefix(c.Body, statusCommentMark)
+}
+
+func genStatusComment(checks []mergeCheck, notice string) string {
+	s := []string{statusCommentMark, statusCommentTitle, "", genMergeCheckTable(checks)}
+
+	if notice != "" {
+		s = append(s, "", notice)
+	}
+
+	return strings.Join(s, "\n")
+}
+
+func genMergeCheckTable(checks []mergeCheck) string {
+	s := make([]string, 0, len(checks)+2)
+	s = append(s, "| Condition | Result | Detail |", "| --- | :---: | --- |")
+
+	for i := range checks {
+		item := &checks[i]
+
+		mark := markFailed
+		if item.passed {
+			mark = markPassed
+		}
+
+		s = append(s, fmt.Sprintf(
+			"| %s | %s | %s |",
+			escapeTableCell(item.name), mark, escapeTableCell(item.detail),
+		))
+	}
+
+	return strings.Join(s, "\n")
+}
+
+func escapeTableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+
+	return strings.ReplaceAll(s, "\n", "<br/>")
+}
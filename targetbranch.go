@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/opensourceways/community-robot-lib/giteeclient"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	checkNameTargetBranch = "Target branch"
+
+	msgTargetBranchEOL        = "The target branch %s has reached its end of life, PR can't be merged into it."
+	msgTargetBranchNotAllowed = "The target branch %s is not allowed, PR can be merged only into the branches matching: %s"
+	msgTargetBranchAllowed    = "The target branch %s is allowed."
+
+	commentInvalidTargetBranch = "@%s , %s Please change the target branch or close this pull request."
+)
+
+func needsCheckingTargetBranch(cfg *botConfig) bool {
+	return len(cfg.AllowedTargetBranches) > 0 || len(cfg.EOLBranches) > 0
+}
+
+// checkTargetBranch returns the reason if the pr can't target the branch.
+func (c *botConfig) checkTargetBranch(branch string) (string, bool) {
+	if matchAnyPattern(c.EOLBranches, branch) {
+		return fmt.Sprintf(msgTargetBranchEOL, branch), false
+	}
+
+	if len(c.AllowedTargetBranches) > 0 && !matchAnyPattern(c.AllowedTargetBranches, branch) {
+		return fmt.Sprintf(
+			msgTargetBranchNotAllowed, branch, strings.Join(c.AllowedTargetBranches, ", "),
+		), false
+	}
+
+	return fmt.Sprintf(msgTargetBranchAllowed, branch), true
+}
+
+func matchAnyPattern(patterns []string, s string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, s); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *mergeHelper) checkTargetBranch() mergeCheck {
+	detail, ok := m.cfg.checkTargetBranch(m.pr.GetBase().GetRef())
+
+	return mergeCheck{name: checkNameTargetBranch, passed: ok, detail: detail}
+}
+
+// handleTargetBranch comments immediately when the pr is opened against or
+// changed to the branch which is end of life or not allowed.
+func (bot *robot) handleTargetBranch(e *sdk.PullRequestEvent, cfg *botConfig, log *logrus.Entry) error {
+	if !needsCheckingTargetBranch(cfg) {
+		return nil
+	}
+
+	if a := giteeclient.GetPullRequestAction(e); a != giteeclient.PRActionOpened &&
+		a != giteeclient.PRActionChangedTargetBranch {
+		return nil
+	}
+
+	pr := giteeclient.GetPRInfoByPREvent(e)
+
+	reason, ok := cfg.checkTargetBranch(pr.BaseRef)
+	if ok {
+		return nil
+	}
+
+	return bot.cli.CreatePRComment(pr.Org, pr.Repo, pr.Number, fmt.Sprintf(
+		commentInvalidTargetBranch, pr.Author, reason,
+	))
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"regexp"
+
+	sdk "gitee.com/openeuler/go-gitee/gitee"
+	"github.com/opensourceways/community-robot-lib/giteeclient"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	wipLabel = "do-not-merge/work-in-progress"
+
+	checkNameWorkInProgress = "Work in progress"
+
+	msgWorkInProgress = "PR is a draft or its title is marked as WIP."
+	msgReadyForReview = "PR is ready for review."
+)
+
+var regWIPTitle = regexp.MustCompile(`(?i)^\s*(\[WIP\]|WIP:)`)
+
+// isWorkInProgress checks whether the pr is a draft or its title starts with [WIP] or WIP:.
+func isWorkInProgress(pr *sdk.PullRequestHook) bool {
+	return pr.Draft || regWIPTitle.MatchString(pr.Title)
+}
+
+func checkWorkInProgress(pr *sdk.PullRequestHook) mergeCheck {
+	if isWorkInProgress(pr) {
+		return mergeCheck{name: checkNameWorkInProgress, detail: msgWorkInProgress}
+	}
+
+	return mergeCheck{name: checkNameWorkInProgress, passed: true, detail: msgReadyForReview}
+}
+
+// handleWorkInProgress manages the wip label when the pr is opened or updated,
+// and checks the reviewer of pr when it leaves draft.
+func (bot *robot) handleWorkInProgress(e *sdk.PullRequestEvent, cfg *botConfig, log *logrus.Entry) error {
+	if giteeclient.GetPullRequestAction(e) != giteeclient.PRActionOpened &&
+		(e.Action == nil || *e.Action != prActionUpdate) {
+		return nil
+	}
+
+	pr := giteeclient.GetPRInfoByPREvent(e)
+	wip := isWorkInProgress(e.GetPullRequest())
+	hasLabel := pr.Labels.Has(wipLabel)
+
+	if wip && !hasLabel {
+		if err := bot.createLabelIfNeed(pr.Org, pr.Repo, wipLabel); err != nil {
+			log.WithError(err).Errorf("create repo label: %s", wipLabel)
+		}
+
+		return bot.cli.AddPRLabel(pr.Org, pr.Repo, pr.Number, wipLabel)
+	}
+
+	if !wip && hasLabel {
+		if err := bot.cli.RemovePRLabel(pr.Org, pr.Repo, pr.Number, wipLabel); err != nil {
+			return err
+		}
+
+		if !cfg.UnableCheckingReviewerForPR {
+			return bot.remindReviewer(e)
+		}
+	}
+
+	return nil
+}